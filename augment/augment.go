@@ -0,0 +1,111 @@
+// Package augment randomly transforms images as they are read for training,
+// so that every pass sees slightly different examples without the dataset being expanded up front.
+//
+// Images are inputs of Height rows of Width pixels of Channels interleaved values,
+// e.g. 28*28 grays of MNIST or 32*32*3 RGB values. Areas moved in from outside an image are 0.
+package augment
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/dataset"
+	"math/rand"
+	"sync/atomic"
+)
+
+// Image is the layout of the pixels of an input.
+type Image struct {
+	Width    int
+	Height   int
+	Channels int
+}
+
+// Gray returns the layout of grayscale images.
+func Gray(width, height int) Image {
+	return Image{Width: width, Height: height, Channels: 1}
+}
+
+// RGB returns the layout of color images.
+func RGB(width, height int) Image {
+	return Image{Width: width, Height: height, Channels: 3}
+}
+
+// Len returns the number of values of an image.
+func (img Image) Len() int {
+	return img.Width * img.Height * img.Channels
+}
+
+// Transform randomly transforms pixels of an image using r.
+// It may modify pixels in place and return them, or return new ones.
+type Transform func(r *rand.Rand, img Image, pixels []float64) []float64
+
+// Dataset returns a dataset applying the transforms in order to the input of every example of ds.
+// Examples of ds are not modified. Transforms draw from a generator seeded with the seed
+// and the number of passes made, so every pass is augmented differently yet reproducibly.
+func Dataset(ds dataset.Dataset, img Image, seed int64, t ...Transform) dataset.Dataset {
+	var pass atomic.Int64
+	return dataset.Func(func() dataset.Iterator {
+		return &iterator{
+			Iterator:   ds.Iter(),
+			r:          rand.New(rand.NewSource(seed + pass.Add(1) - 1)),
+			img:        img,
+			transforms: t,
+		}
+	})
+}
+
+type iterator struct {
+	dataset.Iterator
+	r          *rand.Rand
+	img        Image
+	transforms []Transform
+	err        error
+}
+
+func (it *iterator) Next() (dataset.Example, bool) {
+	if it.err != nil {
+		return dataset.Example{}, false
+	}
+	ex, ok := it.Iterator.Next()
+	if !ok {
+		return ex, false
+	}
+	if len(ex.Input) != it.img.Len() {
+		it.err = fmt.Errorf("augment: input of %d values, image of %dx%dx%d", len(ex.Input), it.img.Width, it.img.Height, it.img.Channels)
+		return dataset.Example{}, false
+	}
+	ex.Input = Apply(it.r, it.img, ex.Input, it.transforms...)
+	return ex, true
+}
+
+func (it *iterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Err()
+}
+
+// Apply applies the transforms in order to a copy of pixels.
+func Apply(r *rand.Rand, img Image, pixels []float64, t ...Transform) []float64 {
+	pixels = append([]float64(nil), pixels...)
+	for _, transform := range t {
+		pixels = transform(r, img, pixels)
+	}
+	return pixels
+}
+
+// Sometimes applies t with probability p.
+func Sometimes(p float64, t Transform) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		if r.Float64() < p {
+			return t(r, img, pixels)
+		}
+		return pixels
+	}
+}
+
+// OneOf applies one of the transforms, picked at random.
+func OneOf(t ...Transform) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		return t[r.Intn(len(t))](r, img, pixels)
+	}
+}
@@ -0,0 +1,191 @@
+package augment
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Shift moves images by up to n whole pixels in each direction.
+func Shift(n int) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		dx, dy := float64(r.Intn(2*n+1)-n), float64(r.Intn(2*n+1)-n)
+		return warp(img, pixels, func(x, y float64) (float64, float64) {
+			return x - dx, y - dy
+		})
+	}
+}
+
+// Rotate rotates images about their center by up to the given degrees either way.
+func Rotate(degrees float64) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		angle := (2*r.Float64() - 1) * degrees * math.Pi / 180
+		sin, cos := math.Sincos(-angle)
+		cx, cy := center(img)
+		return warp(img, pixels, func(x, y float64) (float64, float64) {
+			x, y = x-cx, y-cy
+			return cos*x - sin*y + cx, sin*x + cos*y + cy
+		})
+	}
+}
+
+// Scale zooms images about their center by a factor between low and high, e.g. 0.9 and 1.1.
+func Scale(low, high float64) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		factor := low + r.Float64()*(high-low)
+		cx, cy := center(img)
+		return warp(img, pixels, func(x, y float64) (float64, float64) {
+			return (x-cx)/factor + cx, (y-cy)/factor + cy
+		})
+	}
+}
+
+// Elastic distorts images by random displacements smoothed with a gaussian of sigma pixels
+// and scaled by alpha, as in Simard et al. 2003. For MNIST alpha 34 and sigma 4 work well.
+func Elastic(alpha, sigma float64) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		n := img.Width * img.Height
+		dx, dy := make([]float64, n), make([]float64, n)
+		for i := range n {
+			dx[i] = 2*r.Float64() - 1
+			dy[i] = 2*r.Float64() - 1
+		}
+		kernel := gaussian(sigma)
+		blur(img.Width, img.Height, dx, kernel, alpha)
+		blur(img.Width, img.Height, dy, kernel, alpha)
+		return warp(img, pixels, func(x, y float64) (float64, float64) {
+			i := int(y)*img.Width + int(x)
+			return x + dx[i], y + dy[i]
+		})
+	}
+}
+
+// Noise adds gaussian noise of the given standard deviation to every value.
+func Noise(std float64) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		for i := range pixels {
+			pixels[i] += r.NormFloat64() * std
+		}
+		return pixels
+	}
+}
+
+// Clamp limits values to [low, high], e.g. after Noise.
+func Clamp(low, high float64) Transform {
+	return func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		for i, v := range pixels {
+			pixels[i] = min(max(v, low), high)
+		}
+		return pixels
+	}
+}
+
+// FlipHorizontal mirrors images left to right with probability p.
+// Unlike natural images, digits and letters should not be flipped.
+func FlipHorizontal(p float64) Transform {
+	return Sometimes(p, func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		c := img.Channels
+		for y := range img.Height {
+			row := pixels[y*img.Width*c : (y+1)*img.Width*c]
+			for i, j := 0, img.Width-1; i < j; i, j = i+1, j-1 {
+				for k := range c {
+					row[i*c+k], row[j*c+k] = row[j*c+k], row[i*c+k]
+				}
+			}
+		}
+		return pixels
+	})
+}
+
+// FlipVertical mirrors images top to bottom with probability p.
+func FlipVertical(p float64) Transform {
+	return Sometimes(p, func(r *rand.Rand, img Image, pixels []float64) []float64 {
+		stride := img.Width * img.Channels
+		for i, j := 0, img.Height-1; i < j; i, j = i+1, j-1 {
+			a, b := pixels[i*stride:(i+1)*stride], pixels[j*stride:(j+1)*stride]
+			for k := range a {
+				a[k], b[k] = b[k], a[k]
+			}
+		}
+		return pixels
+	})
+}
+
+func center(img Image) (float64, float64) {
+	return float64(img.Width-1) / 2, float64(img.Height-1) / 2
+}
+
+// warp returns the image whose pixel at x, y is sampled from pixels at source(x, y), interpolating bilinearly.
+func warp(img Image, pixels []float64, source func(x, y float64) (float64, float64)) []float64 {
+	c := img.Channels
+	result := make([]float64, len(pixels))
+	for y := range img.Height {
+		for x := range img.Width {
+			sx, sy := source(float64(x), float64(y))
+			x0, y0 := math.Floor(sx), math.Floor(sy)
+			fx, fy := sx-x0, sy-y0
+			dst := result[(y*img.Width+x)*c : (y*img.Width+x+1)*c]
+			for _, corner := range [4]struct {
+				x, y int
+				w    float64
+			}{
+				{int(x0), int(y0), (1 - fx) * (1 - fy)},
+				{int(x0) + 1, int(y0), fx * (1 - fy)},
+				{int(x0), int(y0) + 1, (1 - fx) * fy},
+				{int(x0) + 1, int(y0) + 1, fx * fy},
+			} {
+				if corner.w == 0 || corner.x < 0 || corner.y < 0 || corner.x >= img.Width || corner.y >= img.Height {
+					continue
+				}
+				src := pixels[(corner.y*img.Width+corner.x)*c:]
+				for k := range dst {
+					dst[k] += corner.w * src[k]
+				}
+			}
+		}
+	}
+	return result
+}
+
+// gaussian returns a normalized kernel of radius 3 sigma.
+func gaussian(sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// blur convolves the width x height field with kernel along both axes and scales it, treating outside values as 0.
+func blur(width, height int, field, kernel []float64, scale float64) {
+	radius := len(kernel) / 2
+	tmp := make([]float64, len(field))
+	for y := range height {
+		for x := range width {
+			var sum float64
+			for i, k := range kernel {
+				if xi := x + i - radius; xi >= 0 && xi < width {
+					sum += k * field[y*width+xi]
+				}
+			}
+			tmp[y*width+x] = sum
+		}
+	}
+	for y := range height {
+		for x := range width {
+			var sum float64
+			for i, k := range kernel {
+				if yi := y + i - radius; yi >= 0 && yi < height {
+					sum += k * tmp[yi*width+x]
+				}
+			}
+			field[y*width+x] = sum * scale
+		}
+	}
+}
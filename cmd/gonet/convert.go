@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+)
+
+func convert(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	in := flags.String("in", "", "network to convert")
+	out := flags.String("out", "", "converted network")
+	from := flags.String("from", "", fmt.Sprintf("format of the input, one of %v, detected from its extension by default", formats))
+	to := flags.String("to", "", "format of the output, detected from its extension by default")
+	activation := flags.String("activation", "", "activation of networks that don't record it (gob)")
+	precision := flags.String("precision", "float64", "precision of the converted weights: float32 or float64")
+	flags.Parse(args)
+
+	if *in == "" || *out == "" {
+		flags.Usage()
+		return fmt.Errorf("missing input or output")
+	}
+	opts, err := activationOpts(*activation)
+	if err != nil {
+		return err
+	}
+	nn, err := loadModel(*in, *from, opts...)
+	if err != nil {
+		return err
+	}
+
+	switch *precision {
+	case "float32":
+		return saveModel(*out, *to, feedforward.Convert[float32](nn))
+	case "float64":
+		return saveModel(*out, *to, nn)
+	default:
+		return fmt.Errorf("unknown precision: %s", *precision)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"text/tabwriter"
+)
+
+func inspect(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	model := flags.String("model", "", "saved network")
+	format := flags.String("format", "", "format of the network, detected from its extension by default")
+	flags.Parse(args)
+
+	if *model == "" {
+		flags.Usage()
+		return fmt.Errorf("missing model")
+	}
+	nn, err := loadModel(*model, *format)
+	if err != nil {
+		return err
+	}
+
+	shapes := nn.Shapes()
+	activation := nn.ActivationName()
+	if activation == "" {
+		activation = "not recorded"
+	}
+	fmt.Printf("Shapes: %v\n", shapes)
+	fmt.Printf("Hidden Layers: %d\n", len(shapes)-2)
+	fmt.Printf("Activation: %s\n", activation)
+	metadata := nn.Metadata()
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Printf("Metadata: %s=%s\n", k, metadata[k])
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Layer\tShape\tParameters\tMin\tMax\tMean\tStd\tZeros\t")
+	total := 0
+	for l := range len(shapes) - 1 {
+		weights, biases := nn.Weights(l), nn.Biases(l)
+		params := len(weights) + len(biases)
+		total += params
+
+		minW, maxW, sum, zeros := math.Inf(1), math.Inf(-1), 0.0, 0
+		for _, v := range weights {
+			minW, maxW = min(minW, v), max(maxW, v)
+			sum += v
+			if v == 0 {
+				zeros++
+			}
+		}
+		mean := sum / float64(len(weights))
+		variance := 0.0
+		for _, v := range weights {
+			variance += (v - mean) * (v - mean)
+		}
+		std := math.Sqrt(variance / float64(len(weights)))
+
+		fmt.Fprintf(w, "%d\t%dx%d\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%.1f%%\t\n",
+			l+1, shapes[l+1], shapes[l], params, minW, maxW, mean, std, 100*float64(zeros)/float64(len(weights)))
+	}
+	fmt.Fprintf(w, "Total\t\t%d\t\t\t\t\t\t\n", total)
+	return w.Flush()
+}
@@ -0,0 +1,59 @@
+// Command gonet trains, runs, inspects, converts and serves feedforward networks.
+//
+//	gonet train -config xor.json
+//	gonet predict -model bin/xor.json < inputs.csv
+//	gonet inspect -model bin/xor.json
+//	gonet convert -in bin/xor.json -out bin/xor.onnx
+//	gonet serve -addr :8080 xor=bin/xor.json
+//	gonet plot -o bin/xor.svg bin/xor-history.csv
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"train":   train,
+	"predict": predict,
+	"inspect": inspect,
+	"convert": convert,
+	"serve":   serveModels,
+	"plot":    plotHistory,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gonet:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: gonet <command> [flags]
+
+commands:
+  train    train a network from a config file
+  predict  print predictions of a network for CSV inputs
+  inspect  print shapes, parameter counts and weight statistics of a network
+  convert  convert a network between formats
+  serve    serve predictions of networks over HTTP
+  plot     chart the loss and metrics of a training history as SVG or PNG
+
+Run gonet <command> -h for the flags of a command.
+`)
+	os.Exit(2)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/npy"
+	"github.com/lnashier/gonet/onnx"
+	"github.com/lnashier/gonet/safetensors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// formats lists the supported model formats, detected by file extension, gob being the default.
+var formats = []string{"gob", "json", "onnx", "npz", "safetensors"}
+
+func formatOf(name, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(name), ".")
+		for _, f := range formats {
+			if f == format {
+				return f, nil
+			}
+		}
+		return "gob", nil
+	}
+	for _, f := range formats {
+		if f == format {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q, want one of %v", format, formats)
+}
+
+// activationOpts returns the options setting the named activation, none if name is empty.
+func activationOpts(name string) ([]feedforward.NetworkOpt, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if _, _, ok := fns.Activation(name); !ok {
+		return nil, fmt.Errorf("unknown activation: %s", name)
+	}
+	return []feedforward.NetworkOpt{feedforward.NamedActivation(name)}, nil
+}
+
+func loadModel(name, format string, opt ...feedforward.NetworkOpt) (*feedforward.Network, error) {
+	format, err := formatOf(name, format)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case "onnx":
+		return onnx.Import(f, opt...)
+	case "npz":
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return npy.Load(f, fi.Size(), opt...)
+	case "safetensors":
+		return safetensors.Load[float64](f, opt...)
+	default:
+		// gob and JSON are told apart by Load
+		return feedforward.Load(f, opt...)
+	}
+}
+
+func saveModel[T fns.Float](name, format string, nn *feedforward.Net[T]) error {
+	format, err := formatOf(name, format)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		err = nn.SaveJSON(f)
+	case "onnx":
+		err = onnx.Export(f, nn)
+	case "npz":
+		err = npy.Save(f, nn)
+	case "safetensors":
+		err = safetensors.Save(f, nn)
+	default:
+		err = nn.Save(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
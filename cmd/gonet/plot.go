@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/plot"
+	"github.com/lnashier/gonet/stats"
+	"strings"
+)
+
+func plotHistory(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("plot", flag.ExitOnError)
+	out := flags.String("o", "", "where to save the charts, as PNG when it ends with .png and as SVG otherwise; defaults to the history with .svg")
+	width := flags.Int("width", 800, "width of the charts")
+	height := flags.Int("height", 400, "height of every chart")
+	export := flags.String("export", "", "also save the history to this file, as CSV when it ends with .csv and as JSON otherwise")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("want one history file (CSV or JSON), got %d", flags.NArg())
+	}
+	name := flags.Arg(0)
+	history, err := stats.Load(name)
+	if err != nil {
+		return err
+	}
+	if len(history.Epochs) == 0 {
+		return fmt.Errorf("%s has no epochs", name)
+	}
+
+	if *export != "" {
+		if err := history.Save(*export); err != nil {
+			return err
+		}
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(name, ".csv")
+		*out = strings.TrimSuffix(*out, ".json") + ".svg"
+	}
+	charts := plot.History(history)
+	if err := plot.Save(*out, *width, *height, charts...); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %d charts of %d epochs to %s\n", len(charts), len(history.Epochs), *out)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/config"
+	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/tabular"
+	"io"
+	"os"
+	"strconv"
+)
+
+func predict(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("predict", flag.ExitOnError)
+	model := flags.String("model", "", "saved network")
+	format := flags.String("format", "", "format of the network, detected from its extension by default")
+	activation := flags.String("activation", "", "activation of networks that don't record it (gob)")
+	header := flags.Bool("header", false, "inputs start with a header row")
+	argmax := flags.Bool("argmax", false, "print the index of the largest output instead of all outputs")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gonet predict -model <file> [flags] [inputs.csv]\n\nReads CSV inputs from the file or standard input and writes CSV predictions to standard output.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *model == "" {
+		flags.Usage()
+		return fmt.Errorf("missing model")
+	}
+	opts, err := activationOpts(*activation)
+	if err != nil {
+		return err
+	}
+	nn, err := loadModel(*model, *format, opts...)
+	if err != nil {
+		return err
+	}
+	if nn.Activation() == nil {
+		return fmt.Errorf("%s doesn't record its activation, set -activation", *model)
+	}
+
+	var r io.Reader = os.Stdin
+	if flags.NArg() > 0 {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	// categorical columns are encoded like the training data
+	opt, err := config.TabularOpts(nn.Metadata())
+	if err != nil {
+		return err
+	}
+	t, err := tabular.Read(r, append(opt, tabular.Header(*header))...)
+	if err != nil {
+		return err
+	}
+	inputs := t.Inputs
+
+	w := csv.NewWriter(os.Stdout)
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(input) != nn.Shapes()[0] {
+			return fmt.Errorf("input %d has %d values, network takes %d", i+1, len(input), nn.Shapes()[0])
+		}
+		output := nn.Predict(input)
+		if *argmax {
+			w.Write([]string{strconv.Itoa(fns.Argmax(output))})
+			continue
+		}
+		record := make([]string, len(output))
+		for j, v := range output {
+			record[j] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
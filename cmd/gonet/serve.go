@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/registry"
+	"github.com/lnashier/gonet/serve"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func serveModels(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	activation := flags.String("activation", "", "activation of networks that don't record it (gob)")
+	keep := flags.Int("keep", 3, "versions of watched models kept for rollbacks")
+	interval := flags.Duration("interval", 5*time.Second, "how often watched directories are scanned")
+	maxBatch := flags.Int("max-batch", 1024, "most inputs of a prediction request, 0 for no limit")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gonet serve [flags] [name=]<file or directory>...\n\nServes predictions of the models over HTTP, named after their files unless named explicitly.\nDirectories are watched, their latest gob or JSON network being served.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing model")
+	}
+	opts, err := activationOpts(*activation)
+	if err != nil {
+		return err
+	}
+
+	srv := serve.New(serve.MaxBatchSize(*maxBatch))
+	for _, arg := range flags.Args() {
+		name, file, ok := strings.Cut(arg, "=")
+		if !ok {
+			file = arg
+			name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if fi, err := os.Stat(file); err == nil && fi.IsDir() {
+			reg, err := registry.New(file,
+				registry.Keep(*keep),
+				registry.Interval(*interval),
+				registry.NetworkOpts(opts...),
+				registry.OnChange(func(v *registry.Version) {
+					fmt.Printf("Serving %s %v from %s\n", name, v.Network.Shapes(), filepath.Join(file, v.ID))
+				}),
+				registry.OnError(func(err error) {
+					fmt.Fprintln(os.Stderr, "gonet:", err)
+				}),
+			)
+			if err != nil {
+				return err
+			}
+			go reg.Watch(ctx)
+			srv.Add(name, reg)
+			continue
+		}
+		nn, err := loadModel(file, "", opts...)
+		if err != nil {
+			return err
+		}
+		if nn.Activation() == nil {
+			return fmt.Errorf("%s doesn't record its activation, set -activation", file)
+		}
+		srv.Add(name, nn)
+		fmt.Printf("Serving %s %v from %s\n", name, nn.Shapes(), file)
+	}
+
+	hs := &http.Server{
+		Addr:              *addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		hs.Shutdown(shutdownCtx)
+	}()
+	fmt.Println("Listening on", *addr)
+	if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
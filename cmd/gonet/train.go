@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/config"
+	"github.com/lnashier/gonet/feedforward"
+)
+
+func train(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	configPath := flags.String("config", "", "training config file (JSON)")
+	out := flags.String("o", "", "where to save the trained network, overrides the config")
+	epochs := flags.Int("epochs", 0, "number of epochs, overrides the config")
+	history := flags.String("history", "", "where to save the statistics of every epoch (CSV or JSON), overrides the config")
+	flags.Parse(args)
+
+	if *configPath == "" {
+		flags.Usage()
+		return fmt.Errorf("missing config")
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		cfg.Model = *out
+	}
+	if *epochs > 0 {
+		cfg.Epochs = *epochs
+	}
+	if *history != "" {
+		cfg.History = *history
+	}
+	if cfg.Model == "" {
+		return fmt.Errorf("config has no model to save to")
+	}
+
+	// any format the command reads can be resumed and written
+	_, err = cfg.Run(ctx,
+		config.Loader(func(name string, opt ...feedforward.NetworkOpt) (*feedforward.Network, error) {
+			return loadModel(name, "", opt...)
+		}),
+		config.Saver(func(name string, nn *feedforward.Network) error {
+			return saveModel(name, "", nn)
+		}),
+	)
+	return err
+}
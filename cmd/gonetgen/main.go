@@ -0,0 +1,97 @@
+// Command gonetgen compiles a saved feedforward network into a standalone Go file.
+//
+//	gonetgen -model bin/xor.json -pkg xor -o xor/predict.go -test xor/predict_test.go
+//
+// Models saved as gob don't record their activation, set it with -activation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lnashier/gonet/codegen"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"math/rand"
+	"os"
+)
+
+func main() {
+	model := flag.String("model", "", "saved network (gob or JSON)")
+	activation := flag.String("activation", "", "activation of the network: sigmoid, tanh, relu or linear")
+	precision := flag.String("precision", "float64", "precision of the generated code: float32 or float64")
+	pkg := flag.String("pkg", "model", "package of the generated file")
+	name := flag.String("func", "Predict", "name of the generated function")
+	out := flag.String("o", "", "generated file, standard output if empty")
+	test := flag.String("test", "", "also generate a test file checking the generated function against the network")
+	samples := flag.Int("samples", 100, "number of random inputs checked by the test file")
+	seed := flag.Int64("seed", 1, "seed of the random inputs checked by the test file")
+	flag.Parse()
+
+	if *model == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch *precision {
+	case "float32":
+		err = generate[float32](*model, *activation, *out, *test, *samples, *seed, codegen.Package(*pkg), codegen.Func(*name))
+	case "float64":
+		err = generate[float64](*model, *activation, *out, *test, *samples, *seed, codegen.Package(*pkg), codegen.Func(*name))
+	default:
+		err = fmt.Errorf("unknown precision: %s", *precision)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate[T fns.Float](model, activation, out, test string, samples int, seed int64, opt ...codegen.Opt) error {
+	f, err := os.Open(model)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var opts []feedforward.NetworkOpt
+	if activation != "" {
+		if _, _, ok := fns.Activation(activation); !ok {
+			return fmt.Errorf("unknown activation: %s", activation)
+		}
+		opts = append(opts, feedforward.NamedActivation(activation))
+	}
+	nn, err := feedforward.LoadNet[T](f, opts...)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if out != "" {
+		if w, err = os.Create(out); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	if err := codegen.Generate(w, nn, opt...); err != nil {
+		return err
+	}
+
+	if test == "" {
+		return nil
+	}
+	r := rand.New(rand.NewSource(seed))
+	inputs := make([][]T, samples)
+	for i := range inputs {
+		inputs[i] = make([]T, nn.Shapes()[0])
+		for j := range inputs[i] {
+			inputs[i][j] = T(2*r.Float64() - 1)
+		}
+	}
+	tw, err := os.Create(test)
+	if err != nil {
+		return err
+	}
+	defer tw.Close()
+	return codegen.GenerateTest(tw, nn, inputs, opt...)
+}
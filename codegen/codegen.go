@@ -0,0 +1,181 @@
+// Package codegen compiles a trained feedforward network into standalone Go source.
+//
+// The generated function has no dependency on gonet: weights are inlined as constants
+// and every node is computed by its own unrolled expression. Products and sums are
+// evaluated in the same order and precision as Net.Predict, so outputs match bit for bit.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"go/format"
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+)
+
+// activations holds the Go source of the activation functions known by name,
+// written to compute exactly what their fns counterparts do.
+var activations = map[string]string{
+	"sigmoid": "return 1 / (1 + math.Exp(-x))",
+	"tanh":    "return math.Tanh(x)",
+	"relu": `if x > 0 {
+		return x
+	}
+	return 0`,
+	"linear": "return x",
+}
+
+// Generate writes a Go file with a function computing the predictions of the network.
+// The activation must have been set with feedforward.NamedActivation.
+func Generate[T fns.Float](w io.Writer, nn *feedforward.Net[T], opt ...Opt) error {
+	opts := defaultOpts
+	opts.apply(opt)
+
+	activation, ok := activations[nn.ActivationName()]
+	if !ok {
+		return fmt.Errorf("codegen: activation %q has no source, set it with feedforward.NamedActivation", nn.ActivationName())
+	}
+
+	typ := typeName[T]()
+	shapes := nn.Shapes()
+
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, "// %s computes the outputs of a feedforward network of shapes %v, activation %s.\n", opts.name, shapes, nn.ActivationName())
+	fmt.Fprintf(body, "// input must hold %d values.\n", shapes[0])
+	fmt.Fprintf(body, "func %s(input []%s) []%s {\n", opts.name, typ, typ)
+	fmt.Fprintf(body, "_ = input[%d]\n", shapes[0]-1)
+
+	prev := func(i int) string { return fmt.Sprintf("input[%d]", i) }
+	for l := range len(shapes) - 1 {
+		in, out := shapes[l], shapes[l+1]
+		weights, biases := nn.Weights(l), nn.Biases(l)
+		if l > 0 {
+			body.WriteString("\n")
+		}
+		fmt.Fprintf(body, "// layer %d\n", l+1)
+		for j := range out {
+			fmt.Fprintf(body, "l%d_%d := activation(%s", l, j, literal(biases[j]))
+			for i := range in {
+				fmt.Fprintf(body, " +\n%s(%s*%s)", typ, prev(i), literal(weights[j*in+i]))
+			}
+			body.WriteString(")\n")
+		}
+		layer := l
+		prev = func(i int) string { return fmt.Sprintf("l%d_%d", layer, i) }
+	}
+
+	fmt.Fprintf(body, "\nreturn []%s{", typ)
+	for i := range shapes[len(shapes)-1] {
+		if i > 0 {
+			body.WriteString(", ")
+		}
+		body.WriteString(prev(i))
+	}
+	body.WriteString("}\n}\n\n")
+
+	// activations are computed in float64, like Net.Predict does
+	name := nn.ActivationName()
+	fmt.Fprintf(body, "func activation(v %s) %s {\nreturn %s(%s(float64(v)))\n}\n\n", typ, typ, typ, name)
+	fmt.Fprintf(body, "func %s(x float64) float64 {\n%s\n}\n", name, activation)
+
+	buf := &bytes.Buffer{}
+	header(buf, opts.pkg)
+	if bytes.Contains(body.Bytes(), []byte("math.")) {
+		buf.WriteString("import \"math\"\n\n")
+	}
+	buf.Write(body.Bytes())
+
+	return write(w, buf.Bytes())
+}
+
+// GenerateTest writes a Go test file checking that the function generated with the same options
+// reproduces, bit for bit, the predictions of the network for the given inputs.
+func GenerateTest[T fns.Float](w io.Writer, nn *feedforward.Net[T], inputs [][]T, opt ...Opt) error {
+	opts := defaultOpts
+	opts.apply(opt)
+
+	typ := typeName[T]()
+	bits := "math.Float64bits"
+	if typ == "float32" {
+		bits = "math.Float32bits"
+	}
+
+	buf := &bytes.Buffer{}
+	header(buf, opts.pkg)
+	buf.WriteString("import (\n\"math\"\n\"testing\"\n)\n\n")
+	fmt.Fprintf(buf, "func Test%s(t *testing.T) {\n", opts.name)
+	fmt.Fprintf(buf, "tests := []struct {\ninput []%s\nwant []%s\n}{\n", typ, typ)
+	for _, input := range inputs {
+		buf.WriteString("{\ninput: " + sliceLiteral(typ, input) + ",\n")
+		buf.WriteString("want: " + sliceLiteral(typ, nn.Predict(input)) + ",\n},\n")
+	}
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, `for i, tt := range tests {
+	got := %s(tt.input)
+	for j := range tt.want {
+		if %s(got[j]) != %s(tt.want[j]) {
+			t.Errorf("input %%d, output %%d: got %%v, want %%v", i, j, got[j], tt.want[j])
+		}
+	}
+}
+}
+`, opts.name, bits, bits)
+
+	return write(w, buf.Bytes())
+}
+
+func header(buf *bytes.Buffer, pkg string) {
+	buf.WriteString("// Code generated by gonet codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+}
+
+func write(w io.Writer, src []byte) error {
+	src, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("codegen: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func typeName[T fns.Float]() string {
+	var zero T
+	return fmt.Sprintf("float%d", 8*unsafe.Sizeof(zero))
+}
+
+// literal formats v as the shortest constant that converts back to exactly v.
+func literal[T fns.Float](v T) string {
+	var zero T
+	bitSize := 8 * int(unsafe.Sizeof(zero))
+	switch {
+	case math.IsNaN(float64(v)):
+		return typeName[T]() + "(math.NaN())"
+	case math.IsInf(float64(v), 0):
+		return fmt.Sprintf("%s(math.Inf(%d))", typeName[T](), int(math.Copysign(1, float64(v))))
+	case v == 0 && math.Signbit(float64(v)):
+		// there are no negative zero constants
+		return typeName[T]() + "(math.Copysign(0, -1))"
+	}
+	s := strconv.FormatFloat(float64(v), 'g', -1, bitSize)
+	if v < 0 {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func sliceLiteral[T fns.Float](typ string, values []T) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("[]" + typ + "{")
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(literal(v))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerate compiles the code generated for random networks in a temporary module
+// and runs the generated test there, which compares its outputs with Predict bit for bit.
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	for _, activation := range []string{"sigmoid", "tanh", "relu", "linear"} {
+		t.Run(activation+"/float64", func(t *testing.T) {
+			testGenerate[float64](t, activation)
+		})
+		t.Run(activation+"/float32", func(t *testing.T) {
+			testGenerate[float32](t, activation)
+		})
+	}
+}
+
+func testGenerate[T fns.Float](t *testing.T, activation string) {
+	rand.Seed(1)
+	nn := feedforward.NewNet[T](
+		feedforward.Shapes([]int{6, 5, 4, 3}),
+		feedforward.NamedActivation(activation),
+	)
+	r := rand.New(rand.NewSource(2))
+	inputs := make([][]T, 50)
+	for i := range inputs {
+		inputs[i] = make([]T, 6)
+		for j := range inputs[i] {
+			inputs[i][j] = T(4*r.Float64() - 2)
+		}
+	}
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, generate func(f *os.File) error) {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := generate(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", func(f *os.File) error {
+		_, err := f.WriteString("module model\n\ngo 1.22\n")
+		return err
+	})
+	write("predict.go", func(f *os.File) error {
+		return Generate(f, nn)
+	})
+	write("predict_test.go", func(f *os.File) error {
+		return GenerateTest(f, nn, inputs)
+	})
+
+	cmd := exec.Command("go", "test", "-count=1", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
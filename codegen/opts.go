@@ -0,0 +1,33 @@
+package codegen
+
+type Opt func(*opts)
+
+type opts struct {
+	pkg  string
+	name string
+}
+
+var defaultOpts = opts{
+	pkg:  "model",
+	name: "Predict",
+}
+
+func (s *opts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Package sets the package of the generated file. Defaults to model.
+func Package(v string) Opt {
+	return func(s *opts) {
+		s.pkg = v
+	}
+}
+
+// Func sets the name of the generated prediction function. Defaults to Predict.
+func Func(v string) Opt {
+	return func(s *opts) {
+		s.name = v
+	}
+}
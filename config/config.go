@@ -0,0 +1,399 @@
+// Package config describes training runs in JSON files, so that experiments
+// can be changed without recompiling.
+//
+//	{
+//	  "network": {"shapes": [2, 4, 1], "activation": "sigmoid"},
+//	  "loss": "mse",
+//	  "metrics": ["accuracy"],
+//	  "optimizer": {"learningRate": 0.5, "batchSize": 1},
+//	  "schedule": {"type": "step", "gamma": 0.5, "step": 1000},
+//	  "data": {"train": {"format": "csv", "path": "xor.csv", "targets": 1}},
+//	  "epochs": 5000,
+//	  "checkpoint": {"path": "bin/xor-{epoch}.json", "every": 1000},
+//	  "model": "bin/xor.json",
+//	  "history": "bin/xor-history.csv"
+//	}
+//
+// Configs are JSON only: YAML would need a third-party parser,
+// a dependency the module doesn't take.
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/help"
+	"github.com/lnashier/gonet/metrics"
+	"os"
+)
+
+// Config describes a training run.
+type Config struct {
+	Network    Network     `json:"network"`
+	Loss       string      `json:"loss"`              // mse, logloss or binarylogloss
+	Metrics    []string    `json:"metrics,omitempty"` // reported every epoch, see metrics.Lookup
+	Optimizer  Optimizer   `json:"optimizer"`
+	Schedule   *Schedule   `json:"schedule,omitempty"`
+	Pruning    *Pruning    `json:"pruning,omitempty"`
+	Data       Data        `json:"data"`
+	Epochs     int         `json:"epochs"`
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+	// Model is where the trained network is saved, as JSON when it ends with .json.
+	Model string `json:"model"`
+	// Resume continues training the network saved at Model, if any.
+	Resume bool `json:"resume"`
+	// History is where the statistics of every epoch are saved, as CSV when it ends with .csv.
+	History string `json:"history,omitempty"`
+}
+
+// Network describes the architecture.
+type Network struct {
+	Shapes     []int  `json:"shapes"`
+	Activation string `json:"activation"`
+}
+
+// Optimizer sets the parameters of stochastic gradient descent.
+type Optimizer struct {
+	LearningRate float64 `json:"learningRate"`
+	BatchSize    int     `json:"batchSize"`
+	Workers      int     `json:"workers"`
+	Hogwild      bool    `json:"hogwild"`
+}
+
+// Schedule changes the learning rate from epoch to epoch.
+type Schedule struct {
+	// Type is constant, step or exponential.
+	Type string `json:"type"`
+	// Gamma multiplies the learning rate every Step epochs, or every epoch if exponential.
+	Gamma float64 `json:"gamma"`
+	Step  int     `json:"step"`
+}
+
+// Pruning removes weights gradually, see feedforward.GradualSparsity.
+type Pruning struct {
+	Initial float64 `json:"initial"`
+	Final   float64 `json:"final"`
+	Begin   int     `json:"begin"`
+	End     int     `json:"end"`
+}
+
+// Data sets what the network is trained on, and optionally validated with.
+type Data struct {
+	Train      Source  `json:"train"`
+	Validation *Source `json:"validation,omitempty"`
+}
+
+// Checkpoint saves the network every given number of epochs while training.
+type Checkpoint struct {
+	// Path is where checkpoints are saved, {epoch} being replaced with the epoch number.
+	Path  string `json:"path"`
+	Every int    `json:"every"`
+}
+
+var losses = map[string]func(predictions, targets [][]float64) float64{
+	"mse":           fns.MeanSquaredError,
+	"logloss":       fns.LogLoss,
+	"binarylogloss": fns.BinaryLogLoss,
+}
+
+var schedules = []string{"constant", "step", "exponential"}
+
+// Load reads and validates the named config file.
+func Load(name string) (*Config, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return c, nil
+}
+
+// Parse decodes and validates a config, filling in defaults for what it leaves out.
+func Parse(b []byte) (*Config, error) {
+	c := &Config{
+		Loss: "mse",
+		Optimizer: Optimizer{
+			LearningRate: 0.1,
+			BatchSize:    1,
+			Workers:      1,
+		},
+		Epochs: 10,
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate reports the first setting that is missing or out of range.
+func (c *Config) Validate() error {
+	if !c.Resume {
+		if len(c.Network.Shapes) < 2 {
+			return errors.New("network needs at least input and output shapes")
+		}
+		if c.Network.Activation == "" {
+			return errors.New("network has no activation")
+		}
+	}
+	for _, s := range c.Network.Shapes {
+		if s <= 0 {
+			return fmt.Errorf("invalid network shapes %v", c.Network.Shapes)
+		}
+	}
+	if c.Network.Activation != "" {
+		if _, _, ok := fns.Activation(c.Network.Activation); !ok {
+			return fmt.Errorf("unknown activation: %s", c.Network.Activation)
+		}
+	}
+	if _, ok := losses[c.Loss]; !ok {
+		return fmt.Errorf("unknown loss: %s", c.Loss)
+	}
+	for _, name := range c.Metrics {
+		if _, ok := metrics.Lookup(name); !ok {
+			return fmt.Errorf("unknown metric %q, want one of %v", name, metrics.Names())
+		}
+	}
+	if c.Optimizer.LearningRate <= 0 {
+		return fmt.Errorf("invalid learning rate %v", c.Optimizer.LearningRate)
+	}
+	if c.Optimizer.BatchSize < 1 || c.Optimizer.Workers < 1 {
+		return fmt.Errorf("invalid batch size %d or workers %d", c.Optimizer.BatchSize, c.Optimizer.Workers)
+	}
+	if s := c.Schedule; s != nil {
+		switch s.Type {
+		case "constant":
+		case "step":
+			if s.Step < 1 {
+				return fmt.Errorf("invalid schedule step %d", s.Step)
+			}
+			fallthrough
+		case "exponential":
+			if s.Gamma <= 0 {
+				return fmt.Errorf("invalid schedule gamma %v", s.Gamma)
+			}
+		default:
+			return fmt.Errorf("unknown schedule %q, want one of %v", s.Type, schedules)
+		}
+	}
+	if p := c.Pruning; p != nil {
+		if p.Initial < 0 || p.Final >= 1 || p.Initial > p.Final || p.Begin > p.End {
+			return fmt.Errorf("invalid pruning %+v", *p)
+		}
+	}
+	if err := c.Data.Train.validate(); err != nil {
+		return fmt.Errorf("train data: %w", err)
+	}
+	if c.Data.Validation != nil {
+		if err := c.Data.Validation.validate(); err != nil {
+			return fmt.Errorf("validation data: %w", err)
+		}
+	}
+	if c.Epochs < 1 {
+		return fmt.Errorf("invalid epochs %d", c.Epochs)
+	}
+	if cp := c.Checkpoint; cp != nil && (cp.Path == "" || cp.Every < 1) {
+		return fmt.Errorf("invalid checkpoint %+v", *cp)
+	}
+	if c.Resume && c.Model == "" {
+		return errors.New("resume needs a model")
+	}
+	return nil
+}
+
+// NetworkOpts returns the options configuring the network and the statistics it reports, but its shapes.
+func (c *Config) NetworkOpts() []feedforward.NetworkOpt {
+	var opt []feedforward.NetworkOpt
+	if c.Network.Activation != "" {
+		opt = append(opt, feedforward.NamedActivation(c.Network.Activation))
+	}
+	opt = append(opt,
+		feedforward.LearningRate(c.Optimizer.LearningRate),
+		feedforward.BatchSize(c.Optimizer.BatchSize),
+		feedforward.Workers(c.Optimizer.Workers),
+		feedforward.Hogwild(c.Optimizer.Hogwild),
+		feedforward.Loss(losses[c.Loss]),
+	)
+	if len(c.Metrics) > 0 {
+		named := make(map[string]metrics.Metric, len(c.Metrics))
+		for _, name := range c.Metrics {
+			named[name], _ = metrics.Lookup(name)
+		}
+		opt = append(opt, feedforward.Metrics(named))
+	}
+	if s := c.Schedule; s != nil {
+		switch s.Type {
+		case "step":
+			opt = append(opt, feedforward.LearningRateSchedule(feedforward.StepDecay(c.Optimizer.LearningRate, s.Gamma, s.Step)))
+		case "exponential":
+			opt = append(opt, feedforward.LearningRateSchedule(feedforward.ExponentialDecay(c.Optimizer.LearningRate, s.Gamma)))
+		}
+	}
+	if p := c.Pruning; p != nil {
+		opt = append(opt, feedforward.PruningSchedule(feedforward.GradualSparsity(p.Initial, p.Final, p.Begin, p.End)))
+	}
+	return opt
+}
+
+// New returns the network to train, the one saved at Model when resuming and it exists,
+// a new one otherwise. Options are added to NetworkOpts.
+func (c *Config) New(opt ...feedforward.NetworkOpt) (*feedforward.Network, error) {
+	return c.network(defaultRunOpts.load, opt)
+}
+
+func (c *Config) network(load func(name string, opt ...feedforward.NetworkOpt) (*feedforward.Network, error), opt []feedforward.NetworkOpt) (*feedforward.Network, error) {
+	opt = append(c.NetworkOpts(), opt...)
+	if c.Resume {
+		nn, err := load(c.Model, opt...)
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			return nn, err
+		}
+		if len(c.Network.Shapes) < 2 || c.Network.Activation == "" {
+			return nil, fmt.Errorf("%s does not exist and network is incomplete", c.Model)
+		}
+	}
+	return feedforward.New(append(opt, feedforward.Shapes(c.Network.Shapes))...), nil
+}
+
+// ValidationOpts returns the options validating the network every epoch, reading the validation data if any.
+// Categorical columns are encoded with the categories of the training data.
+func (c *Config) ValidationOpts() ([]feedforward.NetworkOpt, error) {
+	if c.Data.Validation == nil {
+		return nil, nil
+	}
+	var categories map[string][]string
+	if len(c.Data.Validation.Categorical) > 0 {
+		t, err := c.Data.Train.read(nil)
+		if err != nil {
+			return nil, err
+		}
+		categories = t.Categories
+	}
+	return c.validationOpts(categories)
+}
+
+func (c *Config) validationOpts(categories map[string][]string) ([]feedforward.NetworkOpt, error) {
+	if c.Data.Validation == nil {
+		return nil, nil
+	}
+	t, err := c.Data.Validation.read(categories)
+	if err != nil {
+		return nil, err
+	}
+	return []feedforward.NetworkOpt{feedforward.Validation(t.Inputs, t.Targets)}, nil
+}
+
+// TrainingOpts returns the options saving checkpoints and the history.
+// Losses, metrics and validation are recorded by the network, see NetworkOpts and ValidationOpts.
+func (c *Config) TrainingOpts() ([]help.TrainingOpt, error) {
+	var opt []help.TrainingOpt
+	if c.Checkpoint != nil {
+		opt = append(opt, help.Checkpoint(c.Checkpoint.Path, c.Checkpoint.Every))
+	}
+	if c.History != "" {
+		opt = append(opt, help.History(c.History))
+	}
+	return opt, nil
+}
+
+// RunOpt changes how Run reads and writes networks.
+type RunOpt func(*runOpts)
+
+type runOpts struct {
+	load func(name string, opt ...feedforward.NetworkOpt) (*feedforward.Network, error)
+	save func(name string, nn *feedforward.Network) error
+}
+
+var defaultRunOpts = runOpts{
+	load: help.LoadFeedforward,
+	save: func(name string, nn *feedforward.Network) error {
+		return help.Save(name, nn)
+	},
+}
+
+func (o *runOpts) apply(opts []RunOpt) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Loader sets how a network saved at Model is read when resuming. Defaults to help.LoadFeedforward.
+func Loader(v func(name string, opt ...feedforward.NetworkOpt) (*feedforward.Network, error)) RunOpt {
+	return func(o *runOpts) {
+		o.load = v
+	}
+}
+
+// Saver sets how the trained network is written to Model. Defaults to help.Save.
+func Saver(v func(name string, nn *feedforward.Network) error) RunOpt {
+	return func(o *runOpts) {
+		o.save = v
+	}
+}
+
+// Run trains the network for the configured number of epochs and saves it to Model, if set.
+// Networks trained on CSV data record the categories of their categorical inputs, see TabularOpts.
+func (c *Config) Run(ctx context.Context, opt ...RunOpt) (*feedforward.Network, error) {
+	opts := defaultRunOpts
+	opts.apply(opt)
+
+	t, err := c.Data.Train.read(nil)
+	if err != nil {
+		return nil, err
+	}
+	network, err := c.validationOpts(t.Categories)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := c.Data.Train.metadata(t)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		network = append(network, feedforward.Metadata(metadata))
+	}
+	nn, err := c.network(opts.load, network)
+	if err != nil {
+		return nil, err
+	}
+	inputs, targets := t.Inputs, t.Targets
+	if err := Check(nn, inputs, targets); err != nil {
+		return nil, err
+	}
+	training, err := c.TrainingOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	help.Train(ctx, nn, c.Epochs, inputs, targets, training...)
+
+	if c.Model != "" {
+		if err := opts.save(c.Model, nn); err != nil {
+			return nil, err
+		}
+	}
+	return nn, nil
+}
+
+// Check reports whether data fits the network's input and output shapes.
+func Check(nn *feedforward.Network, inputs, targets [][]float64) error {
+	if len(inputs) == 0 || len(inputs) != len(targets) {
+		return fmt.Errorf("data has %d inputs and %d targets", len(inputs), len(targets))
+	}
+	shapes := nn.Shapes()
+	if len(inputs[0]) != shapes[0] || len(targets[0]) != shapes[len(shapes)-1] {
+		return fmt.Errorf("data has %d inputs and %d targets, network has shapes %v", len(inputs[0]), len(targets[0]), shapes)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/dataset"
+	"github.com/lnashier/gonet/idx"
+	"github.com/lnashier/gonet/tabular"
+	"slices"
+)
+
+// Source is a file of training examples.
+type Source struct {
+	// Format is csv or idx.
+	Format string `json:"format"`
+	// Path is the CSV file, or the IDX file of inputs.
+	Path string `json:"path"`
+	// CSV and TSV (.tsv) files hold inputs followed by Targets target columns,
+	// after a header row if Header is set. Target columns can also be named,
+	// or numbered from 1 without a header, see package tabular.
+	Targets       int      `json:"targets"`
+	TargetColumns []string `json:"targetColumns"`
+	Header        bool     `json:"header"`
+	// Ignore lists columns that are not inputs.
+	Ignore []string `json:"ignore"`
+	// Categorical maps columns to the encoding of their categories, onehot or index.
+	Categorical map[string]string `json:"categorical"`
+	// Missing is what is done with missing values: fail, zero, mean or drop. Defaults to fail.
+	Missing string `json:"missing"`
+	// Labels is the IDX file of class labels, one-hot encoded into Classes targets,
+	// as many as the largest label needs if zero.
+	Labels  string `json:"labels"`
+	Classes int    `json:"classes"`
+}
+
+func (s *Source) validate() error {
+	switch s.Format {
+	case "csv":
+		if s.Targets < 0 || s.Targets == 0 && len(s.TargetColumns) == 0 {
+			return fmt.Errorf("invalid targets %d", s.Targets)
+		}
+		for column, encoding := range s.Categorical {
+			if _, ok := encodings[encoding]; !ok {
+				return fmt.Errorf("unknown encoding %q of column %s, want onehot or index", encoding, column)
+			}
+		}
+		if _, ok := missing[s.Missing]; !ok {
+			return fmt.Errorf("unknown missing values strategy %q, want fail, zero, mean or drop", s.Missing)
+		}
+	case "idx":
+		if s.Labels == "" {
+			return errors.New("idx needs labels")
+		}
+	default:
+		return fmt.Errorf("unknown format %q, want csv or idx", s.Format)
+	}
+	if s.Path == "" {
+		return errors.New("missing path")
+	}
+	return nil
+}
+
+// Read returns the inputs and targets of the source.
+func (s *Source) Read() (inputs, targets [][]float64, err error) {
+	t, err := s.read(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.Inputs, t.Targets, nil
+}
+
+// read returns the examples of the source as a table, encoding the categorical columns of CSV files
+// with the given categories if any. Tables of IDX files only have inputs and targets.
+func (s *Source) read(categories map[string][]string) (*tabular.Table, error) {
+	var t *tabular.Table
+	var err error
+	switch s.Format {
+	case "csv":
+		t, err = s.readCSV(categories)
+	case "idx":
+		t = &tabular.Table{}
+		t.Inputs, t.Targets, err = readIDX(s.Path, s.Labels, s.Classes)
+	default:
+		return nil, fmt.Errorf("unknown data format %q, want csv or idx", s.Format)
+	}
+	if errors.Is(err, dataset.ErrEmpty) || err == nil && len(t.Inputs) == 0 {
+		err = fmt.Errorf("%s: no data", s.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// readCSV reads a CSV or TSV file as described by the source.
+func (s *Source) readCSV(categories map[string][]string) (*tabular.Table, error) {
+	opt := []tabular.Opt{
+		tabular.Header(s.Header),
+		tabular.TrailingTargets(s.Targets),
+		tabular.Targets(s.TargetColumns...),
+		tabular.Ignore(s.Ignore...),
+		tabular.Missing(missing[s.Missing]),
+		tabular.Categories(categories),
+	}
+	for column, encoding := range s.Categorical {
+		opt = append(opt, tabular.Categorical(encodings[encoding], column))
+	}
+	return tabular.Load(s.Path, opt...)
+}
+
+// CategoricalKey is the metadata key under which networks trained on CSV data record their categorical inputs,
+// a JSON object of Categorical by column, see TabularOpts. Metadata is only saved with JSON models.
+const CategoricalKey = "categorical"
+
+// Categorical is a categorical input column of the training data.
+type Categorical struct {
+	// Encoding is onehot or index.
+	Encoding   string   `json:"encoding"`
+	Categories []string `json:"categories"`
+}
+
+// metadata returns the metadata recording the categorical inputs of the table read from the source, if any.
+func (s *Source) metadata(t *tabular.Table) (map[string]string, error) {
+	inputs := map[string]Categorical{}
+	for column, encoding := range s.Categorical {
+		categories, ok := t.Categories[column]
+		if !ok {
+			continue
+		}
+		name := column
+		if encodings[encoding] == tabular.OneHot {
+			name = column + "=" + categories[0]
+		}
+		if slices.Contains(t.InputNames, name) {
+			inputs[column] = Categorical{Encoding: encoding, Categories: categories}
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{CategoricalKey: string(b)}, nil
+}
+
+// TabularOpts returns the options reading CSV inputs of a network the way its training data was read,
+// given the network's metadata: categorical columns are encoded with the categories recorded under CategoricalKey.
+func TabularOpts(metadata map[string]string) ([]tabular.Opt, error) {
+	v, ok := metadata[CategoricalKey]
+	if !ok {
+		return nil, nil
+	}
+	var inputs map[string]Categorical
+	if err := json.Unmarshal([]byte(v), &inputs); err != nil {
+		return nil, fmt.Errorf("metadata %s: %w", CategoricalKey, err)
+	}
+	categories := map[string][]string{}
+	var opt []tabular.Opt
+	for column, c := range inputs {
+		encoding, ok := encodings[c.Encoding]
+		if !ok {
+			return nil, fmt.Errorf("metadata %s: unknown encoding %q of column %s", CategoricalKey, c.Encoding, column)
+		}
+		opt = append(opt, tabular.Categorical(encoding, column))
+		categories[column] = c.Categories
+	}
+	return append(opt, tabular.Categories(categories)), nil
+}
+
+var encodings = map[string]tabular.Encoding{
+	"onehot": tabular.OneHot,
+	"index":  tabular.Index,
+}
+
+var missing = map[string]tabular.Strategy{
+	"":     tabular.Fail,
+	"fail": tabular.Fail,
+	"zero": tabular.Zero,
+	"mean": tabular.Mean,
+	"drop": tabular.Drop,
+}
+
+// readIDX reads IDX inputs and one-hot encodes their labels. Unsigned bytes are taken for pixels, scaled to [0, 1].
+func readIDX(inputsName, labelsName string, classes int) ([][]float64, [][]float64, error) {
+	inputs, err := idx.ReadFile(inputsName)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err := idx.ReadFile(labelsName)
+	if err != nil {
+		return nil, nil, err
+	}
+	opt := []idx.Opt{idx.Classes(classes)}
+	if inputs.Type == idx.Uint8 {
+		opt = append(opt, idx.Scale(1.0/255))
+	}
+	ds, err := idx.Dataset(inputs, labels, opt...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataset.Collect(ds)
+}
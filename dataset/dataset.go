@@ -0,0 +1,280 @@
+// Package dataset streams training examples to networks without holding them all in memory.
+//
+// A Dataset hands out a fresh Iterator per epoch. Datasets of known length that can
+// return any example implement Indexed, which Shuffle builds on; streaming datasets
+// are shuffled approximately with ShuffleBuffer. Prefetch reads ahead in the background.
+//
+//	ds := dataset.Prefetch(dataset.Shuffle(dataset.FromSlices(inputs, targets), 1), 256)
+//	err := nn.TrainDataset(10, ds, func(epoch int) bool { return true })
+package dataset
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Example is an input with its target. Examples handed out by iterators are not modified afterwards.
+type Example struct {
+	Input  []float64
+	Target []float64
+}
+
+// Dataset is a source of examples that can be iterated over any number of times.
+type Dataset interface {
+	// Iter returns an iterator positioned at the first example.
+	Iter() Iterator
+}
+
+// Iterator yields the examples of one pass over a dataset.
+type Iterator interface {
+	// Next returns the next example, false once there are no more or an error occurred.
+	Next() (Example, bool)
+	// Err returns the error that stopped the iteration, if any.
+	Err() error
+	// Close releases the resources of the iterator, it may be called before the end.
+	Close() error
+}
+
+// Indexed is a dataset of known length whose examples can be read in any order.
+type Indexed interface {
+	Dataset
+	Len() int
+	At(i int) Example
+}
+
+// Slices is a dataset held in memory.
+type Slices struct {
+	Inputs  [][]float64
+	Targets [][]float64
+}
+
+// FromSlices returns a dataset of the given inputs and targets, which are not copied.
+// It panics if their lengths differ.
+func FromSlices(inputs, targets [][]float64) *Slices {
+	if len(inputs) != len(targets) {
+		panic(fmt.Sprintf("dataset: %d inputs but %d targets", len(inputs), len(targets)))
+	}
+	return &Slices{Inputs: inputs, Targets: targets}
+}
+
+func (s *Slices) Len() int {
+	return len(s.Inputs)
+}
+
+func (s *Slices) At(i int) Example {
+	return Example{Input: s.Inputs[i], Target: s.Targets[i]}
+}
+
+func (s *Slices) Iter() Iterator {
+	return IterIndexed(s, nil)
+}
+
+// IterIndexed returns an iterator over the examples of ds in the given order, in index order if nil.
+func IterIndexed(ds Indexed, order []int) Iterator {
+	return &indexedIterator{ds: ds, order: order}
+}
+
+type indexedIterator struct {
+	ds    Indexed
+	order []int
+	i     int
+}
+
+func (it *indexedIterator) Next() (Example, bool) {
+	n := len(it.order)
+	if it.order == nil {
+		n = it.ds.Len()
+	}
+	if it.i >= n {
+		return Example{}, false
+	}
+	i := it.i
+	if it.order != nil {
+		i = it.order[i]
+	}
+	it.i++
+	return it.ds.At(i), true
+}
+
+func (it *indexedIterator) Err() error {
+	return nil
+}
+
+func (it *indexedIterator) Close() error {
+	return nil
+}
+
+// Func is a streaming dataset whose iterators are created by calling it.
+type Func func() Iterator
+
+func (f Func) Iter() Iterator {
+	return f()
+}
+
+// Generate returns a streaming dataset from a function that passes every example to yield,
+// stopping early when yield returns false. The function runs once per pass, in its own goroutine.
+func Generate(gen func(yield func(Example) bool) error) Dataset {
+	return Func(func() Iterator {
+		it := &chanIterator{
+			c:    make(chan Example),
+			done: make(chan struct{}),
+		}
+		go func() {
+			defer close(it.c)
+			it.err = gen(func(ex Example) bool {
+				select {
+				case it.c <- ex:
+					return true
+				case <-it.done:
+					return false
+				}
+			})
+		}()
+		return it
+	})
+}
+
+// chanIterator receives examples sent by a goroutine, which sets err before closing c.
+type chanIterator struct {
+	c        chan Example
+	done     chan struct{}
+	err      error
+	finished bool
+	closed   bool
+}
+
+func (it *chanIterator) Next() (Example, bool) {
+	if it.finished || it.closed {
+		return Example{}, false
+	}
+	ex, ok := <-it.c
+	if !ok {
+		it.finished = true
+	}
+	return ex, ok
+}
+
+func (it *chanIterator) Err() error {
+	if !it.finished {
+		return nil
+	}
+	return it.err
+}
+
+func (it *chanIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	close(it.done)
+	for range it.c {
+		// unblock the sender
+	}
+	return nil
+}
+
+// Map returns a dataset whose examples are transformed by f as they are read.
+func Map(ds Dataset, f func(Example) Example) Dataset {
+	if ix, ok := ds.(Indexed); ok {
+		return &mappedIndexed{ix, f}
+	}
+	return Func(func() Iterator {
+		return &mappedIterator{ds.Iter(), f}
+	})
+}
+
+type mappedIndexed struct {
+	Indexed
+	f func(Example) Example
+}
+
+func (m *mappedIndexed) At(i int) Example {
+	return m.f(m.Indexed.At(i))
+}
+
+func (m *mappedIndexed) Iter() Iterator {
+	return IterIndexed(m, nil)
+}
+
+type mappedIterator struct {
+	Iterator
+	f func(Example) Example
+}
+
+func (m *mappedIterator) Next() (Example, bool) {
+	ex, ok := m.Iterator.Next()
+	if !ok {
+		return ex, false
+	}
+	return m.f(ex), true
+}
+
+// NextBatch appends up to size examples of it to inputs and targets.
+// Fewer than size examples are appended once the iterator is exhausted.
+func NextBatch(it Iterator, size int, inputs, targets [][]float64) ([][]float64, [][]float64) {
+	for range size {
+		ex, ok := it.Next()
+		if !ok {
+			break
+		}
+		inputs = append(inputs, ex.Input)
+		targets = append(targets, ex.Target)
+	}
+	return inputs, targets
+}
+
+// Collect reads all examples of ds into memory.
+func Collect(ds Dataset) (inputs, targets [][]float64, err error) {
+	if s, ok := ds.(*Slices); ok {
+		if len(s.Inputs) == 0 {
+			return nil, nil, ErrEmpty
+		}
+		return s.Inputs, s.Targets, nil
+	}
+	if ix, ok := ds.(Indexed); ok {
+		inputs = make([][]float64, 0, ix.Len())
+		targets = make([][]float64, 0, ix.Len())
+	}
+	it := ds.Iter()
+	defer it.Close()
+	for {
+		ex, ok := it.Next()
+		if !ok {
+			break
+		}
+		inputs = append(inputs, ex.Input)
+		targets = append(targets, ex.Target)
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, nil, ErrEmpty
+	}
+	return inputs, targets, nil
+}
+
+// ErrEmpty is returned when a dataset has no examples.
+var ErrEmpty = errors.New("dataset: no examples")
+
+// Subset returns the examples of ds at the given indices, e.g. one part of a split.
+func Subset(ds Indexed, indices []int) Indexed {
+	return &subset{Indexed: ds, indices: indices}
+}
+
+type subset struct {
+	Indexed
+	indices []int
+}
+
+func (s *subset) Len() int {
+	return len(s.indices)
+}
+
+func (s *subset) At(i int) Example {
+	return s.Indexed.At(s.indices[i])
+}
+
+func (s *subset) Iter() Iterator {
+	return IterIndexed(s, nil)
+}
@@ -0,0 +1,30 @@
+package dataset
+
+// Prefetch returns a dataset reading up to n examples of ds ahead in a background goroutine,
+// so that reading and decoding overlap with training.
+func Prefetch(ds Dataset, n int) Dataset {
+	return Func(func() Iterator {
+		src := ds.Iter()
+		it := &chanIterator{
+			c:    make(chan Example, max(n, 1)),
+			done: make(chan struct{}),
+		}
+		go func() {
+			defer close(it.c)
+			defer src.Close()
+			for {
+				ex, ok := src.Next()
+				if !ok {
+					it.err = src.Err()
+					return
+				}
+				select {
+				case it.c <- ex:
+				case <-it.done:
+					return
+				}
+			}
+		}()
+		return it
+	})
+}
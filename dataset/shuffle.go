@@ -0,0 +1,71 @@
+package dataset
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Shuffle returns a dataset visiting the examples of ds in a new random order every pass.
+// Orders only depend on the seed and the number of passes made.
+func Shuffle(ds Indexed, seed int64) Indexed {
+	return &shuffled{Indexed: ds, seed: seed}
+}
+
+type shuffled struct {
+	Indexed
+	seed int64
+	pass atomic.Int64
+}
+
+func (s *shuffled) Iter() Iterator {
+	r := rand.New(rand.NewSource(s.seed + s.pass.Add(1) - 1))
+	return IterIndexed(s.Indexed, r.Perm(s.Len()))
+}
+
+// ShuffleBuffer returns a dataset shuffling the stream of ds through a buffer of the given size:
+// every example read replaces a randomly picked one of the buffer, which is handed out instead.
+// Larger buffers shuffle better, one as large as the dataset shuffles it fully.
+func ShuffleBuffer(ds Dataset, size int, seed int64) Dataset {
+	var pass atomic.Int64
+	return Func(func() Iterator {
+		return &bufferIterator{
+			Iterator: ds.Iter(),
+			r:        rand.New(rand.NewSource(seed + pass.Add(1) - 1)),
+			size:     max(size, 1),
+		}
+	})
+}
+
+type bufferIterator struct {
+	Iterator
+	r      *rand.Rand
+	size   int
+	buffer []Example
+	filled bool
+}
+
+func (it *bufferIterator) Next() (Example, bool) {
+	if !it.filled {
+		for len(it.buffer) < it.size {
+			ex, ok := it.Iterator.Next()
+			if !ok {
+				break
+			}
+			it.buffer = append(it.buffer, ex)
+		}
+		it.filled = true
+	}
+	if len(it.buffer) == 0 {
+		return Example{}, false
+	}
+	i := it.r.Intn(len(it.buffer))
+	ex := it.buffer[i]
+	if next, ok := it.Iterator.Next(); ok {
+		it.buffer[i] = next
+	} else {
+		last := len(it.buffer) - 1
+		it.buffer[i] = it.buffer[last]
+		it.buffer = it.buffer[:last]
+	}
+	return ex, true
+}
@@ -2,11 +2,15 @@ package mnist
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/lnashier/gonet"
+	"github.com/lnashier/gonet/augment"
+	"github.com/lnashier/gonet/dataset"
 	"github.com/lnashier/gonet/feedforward"
 	"github.com/lnashier/gonet/fns"
 	"github.com/lnashier/gonet/help"
+	"github.com/lnashier/gonet/metrics"
 	"time"
 )
 
@@ -16,6 +20,8 @@ func getModel(name string) (*feedforward.Network, bool) {
 		feedforward.Activation(fns.Sigmoid),
 		feedforward.ActivationDerivative(fns.SigmoidDerivative),
 		feedforward.LearningRate(0.1),
+		feedforward.Loss(fns.LogLoss),
+		feedforward.Metrics(map[string]metrics.Metric{"accuracy": metrics.Accuracy}),
 	)
 	if nn == nil {
 		return feedforward.New(
@@ -23,12 +29,14 @@ func getModel(name string) (*feedforward.Network, bool) {
 			feedforward.Activation(fns.Sigmoid),
 			feedforward.ActivationDerivative(fns.SigmoidDerivative),
 			feedforward.LearningRate(0.1),
+			feedforward.Loss(fns.LogLoss),
+			feedforward.Metrics(map[string]metrics.Metric{"accuracy": metrics.Accuracy}),
 		), false
 	}
 	return nn, true
 }
 
-func test(ctx context.Context, nn gonet.Network, inputs [][][]uint8, targets []uint8) {
+func test(ctx context.Context, nn gonet.Network, ds dataset.Indexed) {
 	var correctPredictions int
 	var predictions int
 
@@ -37,21 +45,22 @@ func test(ctx context.Context, nn gonet.Network, inputs [][][]uint8, targets []u
 		fmt.Printf("Total Predictions: %d, Correct Predictions: %d, Accuracy: %.2f%%\n", predictions, correctPredictions, accuracy*100)
 	}()
 
-	for i, input := range inputs {
+	for i := range ds.Len() {
 		select {
 		case <-ctx.Done():
 			return
 		default:
 		}
-		prediction := nn.Predict(flattenImage(input))
+		ex := ds.At(i)
+		prediction := nn.Predict(ex.Input)
 		predictions++
 		output := fns.Argmax(prediction)
-		if output == int(targets[i]) {
+		if output == fns.Argmax(ex.Target) {
 			correctPredictions++
 		} else {
 			// uncomment to save wrong predictions
 			/*
-				err := saveImage(input, fmt.Sprintf("bin/wrong/image_%d-p%d-r%d.png", i, output, targets[i]))
+				err := saveImage(ex.Input, fmt.Sprintf("bin/wrong/image_%d-p%d-r%d.png", i, output, fns.Argmax(ex.Target)))
 				if err != nil {
 					panic(err)
 				}
@@ -61,6 +70,11 @@ func test(ctx context.Context, nn gonet.Network, inputs [][][]uint8, targets []u
 }
 
 func Build(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("mnist", flag.ExitOnError)
+	augmentImages := flags.Bool("augment", false, "shift, rotate, scale and distort training images on the fly")
+	flags.Parse(args)
+	args = flags.Args()
+
 	name := "bin/mnist"
 
 	nn, loaded := getModel(name)
@@ -76,36 +90,47 @@ func Build(ctx context.Context, args []string) {
 
 	// resuming training or not trained
 	if (len(args) > 4 && args[4] == "1") || !loaded {
-		images, labels, err := readData(args[0], args[1])
+		train, err := readData(args[0], args[1])
 		if err != nil {
 			panic(err)
 		}
 
-		fmt.Printf("Normalizing %d images and %d labels\n", len(images), len(labels))
+		fmt.Printf("Read %d images\n", train.Len())
+
+		fmt.Println("Testing on training-data before (re)training")
+		test(ctx, nn, train)
+
+		var ds dataset.Dataset = train
+		if *augmentImages {
+			// every pass sees the images shuffled and slightly moved, turned, resized and distorted
+			ds = augment.Dataset(dataset.Shuffle(train, 1), augment.Gray(28, 28), 1,
+				augment.Shift(2),
+				augment.Rotate(10),
+				augment.Scale(0.9, 1.1),
+				augment.Sometimes(0.5, augment.Elastic(34, 4)),
+				augment.Clamp(0, 1),
+			)
+		}
 
-		inputs, targets, err := normalizeData(10, images, labels)
+		// images are converted while the network trains on the previous ones
+		err = help.TrainDataset(ctx, nn, 10, dataset.Prefetch(ds, 1024))
 		if err != nil {
 			panic(err)
 		}
 
-		fmt.Println("Testing on training-data before (re)training")
-		test(ctx, nn, images, labels)
-
-		help.Train(ctx, nn, 10, inputs, targets, help.LossFunc(fns.LogLoss))
-
 		err = help.Save(name, nn)
 		if err != nil {
 			panic(err)
 		}
 
 		fmt.Println("Testing on training-data after (re)training")
-		test(ctx, nn, images, labels)
+		test(ctx, nn, train)
 	}
 
-	unseenInputs, unseenTargets, err := readData(args[2], args[3])
+	unseen, err := readData(args[2], args[3])
 	if err != nil {
 		panic(err)
 	}
 
-	test(ctx, nn, unseenInputs, unseenTargets)
+	test(ctx, nn, unseen)
 }
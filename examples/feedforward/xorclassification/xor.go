@@ -16,6 +16,7 @@ func getModel(name string) (*feedforward.Network, bool) {
 		feedforward.Activation(fns.Sigmoid),
 		feedforward.ActivationDerivative(fns.SigmoidDerivative),
 		feedforward.LearningRate(0.25),
+		feedforward.Loss(fns.LogLoss),
 	)
 	if nn == nil {
 		return feedforward.New(
@@ -23,6 +24,7 @@ func getModel(name string) (*feedforward.Network, bool) {
 			feedforward.Activation(fns.Sigmoid),
 			feedforward.ActivationDerivative(fns.SigmoidDerivative),
 			feedforward.LearningRate(0.25),
+			feedforward.Loss(fns.LogLoss),
 		), false
 	}
 	return nn, true
@@ -104,7 +106,7 @@ func Build(ctx context.Context, args []string) {
 		fmt.Println("Testing on training-data before (re)training")
 		test(ctx, nn, inputs, labels)
 
-		help.Train(ctx, nn, 10000, inputs, targets, help.EchoStatsEvery(5*time.Second), help.LossFunc(fns.LogLoss))
+		help.Train(ctx, nn, 10000, inputs, targets, help.EchoStatsEvery(5*time.Second))
 
 		if err := help.Save(name, nn); err != nil {
 			panic(err)
@@ -0,0 +1,202 @@
+package feedforward
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"slices"
+	"unsafe"
+)
+
+// JSON model format, version 1:
+//
+//	{
+//	  "format": "gonet/feedforward",
+//	  "version": 1,
+//	  "precision": "float64",           // precision of the saved network, float32 or float64
+//	  "shapes": [2, 4, 1],              // nodes per layer, input layer first
+//	  "activation": "sigmoid",          // name of the activation, empty if set by function
+//	  "learningRate": 0.1,
+//	  "metadata": {"key": "value"},
+//	  "layers": [                       // one per layer after the input layer
+//	    {
+//	      "biases": [0.1, 0.2, 0.3, 0.4],
+//	      "weights": [                  // one row per node, one weight per node of the previous layer
+//	        [0.5, 0.6],
+//	        ...
+//	      ],
+//	      "pruned": [0, 3]              // optional, pruned weights as row-major indices
+//	    },
+//	    ...
+//	  ]
+//	}
+const (
+	jsonFormat  = "gonet/feedforward"
+	jsonVersion = 1
+)
+
+type jsonModel struct {
+	Format       string            `json:"format"`
+	Version      int               `json:"version"`
+	Precision    string            `json:"precision"`
+	Shapes       []int             `json:"shapes"`
+	Activation   string            `json:"activation,omitempty"`
+	LearningRate float64           `json:"learningRate,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Layers       []jsonLayer       `json:"layers"`
+}
+
+type jsonLayer struct {
+	Biases  []float64   `json:"biases"`
+	Weights [][]float64 `json:"weights"`
+	Pruned  []int       `json:"pruned,omitempty"`
+}
+
+// SaveJSON writes the network in the JSON model format, which Load reads as well as gob.
+// Every row of weights is written on its own line so that saved models diff well.
+func (nn *Net[T]) SaveJSON(w io.Writer) error {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	var zero T
+	m := jsonModel{
+		Format:       jsonFormat,
+		Version:      jsonVersion,
+		Precision:    fmt.Sprintf("float%d", 8*unsafe.Sizeof(zero)),
+		Shapes:       nn.shapes,
+		Activation:   nn.afName,
+		LearningRate: nn.lr,
+		Metadata:     nn.metadata,
+	}
+	header, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// layers are written by hand to keep each row of weights on one line
+	buf := bytes.NewBuffer(header[:bytes.LastIndex(header, []byte(`"layers"`))])
+	buf.WriteString(`"layers": [`)
+	for l, d := range nn.layers {
+		if l > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n    {\n      \"biases\": ")
+		if err := writeJSON(buf, fns.Convert[float64](d.b)); err != nil {
+			return err
+		}
+		buf.WriteString(",\n      \"weights\": [")
+		for j := range d.out {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n        ")
+			if err := writeJSON(buf, fns.Convert[float64](d.w[j*d.in:(j+1)*d.in])); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n      ]")
+		if d.pruned != nil {
+			var pruned []int
+			for i, p := range d.pruned {
+				if p {
+					pruned = append(pruned, i)
+				}
+			}
+			buf.WriteString(",\n      \"pruned\": ")
+			if err := writeJSON(buf, pruned); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n    }")
+	}
+	buf.WriteString("\n  ]\n}\n")
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func writeJSON(buf *bytes.Buffer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// isJSON reports whether the buffered content starts as a JSON object.
+func isJSON(r *bufio.Reader) bool {
+	b, _ := r.Peek(512)
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if len(b) < 2 || b[0] != '{' {
+		return false
+	}
+	b = bytes.TrimLeft(b[1:], " \t\r\n")
+	return len(b) > 0 && (b[0] == '"' || b[0] == '}')
+}
+
+func loadJSON[T fns.Float](r io.Reader, opt []NetworkOpt) (*Net[T], error) {
+	var m jsonModel
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.Format != jsonFormat {
+		return nil, fmt.Errorf("unknown model format: %q", m.Format)
+	}
+	if m.Version != jsonVersion {
+		return nil, fmt.Errorf("unsupported model version: %d", m.Version)
+	}
+	if len(m.Shapes) < 2 || slices.Min(m.Shapes) < 1 {
+		return nil, fmt.Errorf("model has invalid shapes %v", m.Shapes)
+	}
+	if len(m.Layers) != len(m.Shapes)-1 {
+		return nil, fmt.Errorf("model has %d layers for shapes %v", len(m.Layers), m.Shapes)
+	}
+
+	// saved settings come first, options override them
+	opts := defaultNetworkOpts
+	if m.Activation != "" {
+		if _, _, ok := fns.Activation(m.Activation); !ok {
+			return nil, fmt.Errorf("unknown activation: %q", m.Activation)
+		}
+		NamedActivation(m.Activation)(&opts)
+	}
+	if m.LearningRate != 0 {
+		opts.learningRate = m.LearningRate
+	}
+	opts.metadata = m.Metadata
+	opts.apply(opt)
+
+	nn := &Net[T]{shapes: m.Shapes}
+	for l, layer := range m.Layers {
+		d := newDense[T](m.Shapes[l], m.Shapes[l+1])
+		if len(layer.Biases) != d.out || len(layer.Weights) != d.out {
+			return nil, fmt.Errorf("layer %d: want %d nodes, got %d biases and %d rows of weights", l, d.out, len(layer.Biases), len(layer.Weights))
+		}
+		convert(d.b, layer.Biases)
+		for j, row := range layer.Weights {
+			if len(row) != d.in {
+				return nil, fmt.Errorf("layer %d: node %d has %d weights, want %d", l, j, len(row), d.in)
+			}
+			convert(d.w[j*d.in:(j+1)*d.in], row)
+		}
+		if layer.Pruned != nil {
+			d.pruned = make([]bool, len(d.w))
+			for _, i := range layer.Pruned {
+				if i < 0 || i >= len(d.w) {
+					return nil, fmt.Errorf("layer %d: pruned weight %d out of range", l, i)
+				}
+				d.pruned[i] = true
+			}
+			d.mask()
+		}
+		nn.layers = append(nn.layers, d)
+	}
+
+	nn.configure(opts)
+
+	return nn, nil
+}
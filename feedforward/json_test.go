@@ -0,0 +1,76 @@
+package feedforward
+
+import (
+	"bytes"
+	"github.com/lnashier/gonet/fns"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs, targets := testData(32, 4, 3)
+	nn := Convert[float32](testNet([]int{4, 6, 3}, Metadata(map[string]string{"dataset": "test", "version": "2"})))
+	nn.PruneGlobal(0.5)
+
+	var buf bytes.Buffer
+	if err := nn.SaveJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadNet[float32](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(loaded.Shapes(), nn.Shapes()) || loaded.ActivationName() != "sigmoid" {
+		t.Fatalf("got %v %s", loaded.Shapes(), loaded.ActivationName())
+	}
+	if !maps.Equal(loaded.Metadata(), nn.Metadata()) {
+		t.Fatalf("got metadata %v, want %v", loaded.Metadata(), nn.Metadata())
+	}
+	for l := range len(nn.Shapes()) - 1 {
+		if !slices.Equal(loaded.Weights(l), nn.Weights(l)) || !slices.Equal(loaded.Biases(l), nn.Biases(l)) {
+			t.Fatalf("layer %d differs", l)
+		}
+	}
+	if loaded.Sparsity() != nn.Sparsity() {
+		t.Fatalf("sparsity %v, want %v", loaded.Sparsity(), nn.Sparsity())
+	}
+
+	// pruned weights stay pruned while training
+	in32, t32 := fns.ConvertMat[float32](inputs), fns.ConvertMat[float32](targets)
+	nn.Train(3, in32, t32, func(int) bool { return true })
+	loaded.Train(3, in32, t32, func(int) bool { return true })
+	for l := range len(nn.Shapes()) - 1 {
+		if !slices.Equal(loaded.Weights(l), nn.Weights(l)) {
+			t.Fatalf("layer %d differs once trained", l)
+		}
+	}
+}
+
+func TestLoadJSONInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testNet([]int{2, 3, 1}).SaveJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.String()
+
+	tests := map[string][2]string{
+		"activation": {`"activation": "sigmoid"`, `"activation": "swish"`},
+		"version":    {`"version": 1`, `"version": 2`},
+		"format":     {`"format": "gonet/feedforward"`, `"format": "other"`},
+	}
+	for name, replace := range tests {
+		t.Run(name, func(t *testing.T) {
+			if !strings.Contains(valid, replace[0]) {
+				t.Fatalf("no %s in %s", replace[0], valid)
+			}
+			_, err := Load(strings.NewReader(strings.Replace(valid, replace[0], replace[1], 1)))
+			if err == nil {
+				t.Fatal("loaded an invalid model")
+			}
+			t.Log(err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package feedforward
+
+import "github.com/lnashier/gonet/fns"
+
+// Matrices are dense, row-major and contiguous. Kernels walk them in square tiles
+// so that the rows touched by the inner loops stay in cache.
+const blockSize = 64
+
+// gemmNT computes c += a * bᵀ, where a is m x k, b is n x k and c is m x n.
+// Both operands are read along their rows, which is the layout of a dense layer
+// (out x in) multiplied with a batch of activations (batch x in).
+func gemmNT[T fns.Float](m, n, k int, a, b, c []T) {
+	for i0 := 0; i0 < m; i0 += blockSize {
+		i1 := min(i0+blockSize, m)
+		for j0 := 0; j0 < n; j0 += blockSize {
+			j1 := min(j0+blockSize, n)
+			for i := i0; i < i1; i++ {
+				ai := a[i*k : i*k+k]
+				ci := c[i*n : i*n+n]
+				for j := j0; j < j1; j++ {
+					ci[j] = dot(ci[j], ai, b[j*k:j*k+k])
+				}
+			}
+		}
+	}
+}
+
+// gemmNN computes c += a * b, where a is m x k, b is k x n and c is m x n.
+func gemmNN[T fns.Float](m, n, k int, a, b, c []T) {
+	for i0 := 0; i0 < m; i0 += blockSize {
+		i1 := min(i0+blockSize, m)
+		for p0 := 0; p0 < k; p0 += blockSize {
+			p1 := min(p0+blockSize, k)
+			for i := i0; i < i1; i++ {
+				ci := c[i*n : i*n+n]
+				for p := p0; p < p1; p++ {
+					axpy(a[i*k+p], b[p*n:p*n+n], ci)
+				}
+			}
+		}
+	}
+}
+
+// gemmTN computes c += aᵀ * b, where a is k x m, b is k x n and c is m x n.
+func gemmTN[T fns.Float](m, n, k int, a, b, c []T) {
+	for p0 := 0; p0 < k; p0 += blockSize {
+		p1 := min(p0+blockSize, k)
+		for i0 := 0; i0 < m; i0 += blockSize {
+			i1 := min(i0+blockSize, m)
+			for p := p0; p < p1; p++ {
+				bp := b[p*n : p*n+n]
+				for i := i0; i < i1; i++ {
+					axpy(a[p*m+i], bp, c[i*n:i*n+n])
+				}
+			}
+		}
+	}
+}
+
+// dot returns sum + x·y, accumulating strictly left to right.
+// The explicit conversion keeps the compiler from fusing multiply and add,
+// so results are identical on every platform.
+func dot[T fns.Float](sum T, x, y []T) T {
+	y = y[:len(x)]
+	for i, v := range x {
+		sum += T(v * y[i])
+	}
+	return sum
+}
+
+// axpy computes y += alpha * x.
+func axpy[T fns.Float](alpha T, x, y []T) {
+	if alpha == 0 {
+		return
+	}
+	y = y[:len(x)]
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+}
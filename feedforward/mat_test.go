@@ -0,0 +1,247 @@
+package feedforward
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// scalarNet is the node by node implementation the matrix kernels replaced,
+// kept as a reference for correctness and speed.
+type scalarNet struct {
+	weights [][][]float64 // layer, node, input
+	biases  [][]float64
+	af, fd  func(float64) float64
+	lr      float64
+}
+
+func newScalarNet(nn *Network) *scalarNet {
+	s := &scalarNet{af: nn.af, fd: nn.fd, lr: nn.lr}
+	for _, d := range nn.layers {
+		var w [][]float64
+		for j := range d.out {
+			w = append(w, append([]float64(nil), d.w[j*d.in:(j+1)*d.in]...))
+		}
+		s.weights = append(s.weights, w)
+		s.biases = append(s.biases, append([]float64(nil), d.b...))
+	}
+	return s
+}
+
+func (s *scalarNet) forward(x []float64, l int) []float64 {
+	a := make([]float64, len(s.weights[l]))
+	for i, w := range s.weights[l] {
+		sum := s.biases[l][i]
+		for j := range x {
+			sum += x[j] * w[j]
+		}
+		a[i] = s.af(sum)
+	}
+	return a
+}
+
+func (s *scalarNet) predict(x []float64) []float64 {
+	for l := range s.weights {
+		x = s.forward(x, l)
+	}
+	return x
+}
+
+func (s *scalarNet) backward(input, target []float64) {
+	activations := make([][]float64, len(s.weights))
+	x := input
+	for l := range s.weights {
+		x = s.forward(x, l)
+		activations[l] = x
+	}
+	deltas := make([][]float64, len(s.weights))
+	for l := len(s.weights) - 1; l >= 0; l-- {
+		deltas[l] = make([]float64, len(s.weights[l]))
+		for i := range deltas[l] {
+			var err float64
+			if l+1 < len(s.weights) {
+				for j, w := range s.weights[l+1] {
+					err += deltas[l+1][j] * w[i]
+				}
+			} else {
+				err = target[i] - activations[l][i]
+			}
+			deltas[l][i] = err * s.fd(activations[l][i])
+		}
+	}
+	for l := range s.weights {
+		prev := input
+		if l > 0 {
+			prev = activations[l-1]
+		}
+		for i, w := range s.weights[l] {
+			s.biases[l][i] += s.lr * deltas[l][i]
+			for j := range w {
+				w[j] += s.lr * prev[j] * deltas[l][i]
+			}
+		}
+	}
+}
+
+func testNet(shapes []int, opt ...NetworkOpt) *Network {
+	rand.Seed(1)
+	return New(append([]NetworkOpt{
+		Shapes(shapes),
+		NamedActivation("sigmoid"),
+		LearningRate(0.1),
+	}, opt...)...)
+}
+
+func testData(n, in, out int) (inputs, targets [][]float64) {
+	r := rand.New(rand.NewSource(2))
+	for range n {
+		x := make([]float64, in)
+		for i := range x {
+			x[i] = r.Float64()
+		}
+		t := make([]float64, out)
+		t[r.Intn(out)] = 1
+		inputs, targets = append(inputs, x), append(targets, t)
+	}
+	return inputs, targets
+}
+
+func TestKernelsMatchScalar(t *testing.T) {
+	nn := testNet([]int{3, 8, 5, 2})
+	ref := newScalarNet(nn)
+	inputs, targets := testData(20, 3, 2)
+
+	nn.Train(3, inputs, targets, func(int) bool { return true })
+	for range 3 {
+		for i := range inputs {
+			ref.backward(inputs[i], targets[i])
+		}
+	}
+
+	for i, x := range inputs {
+		got, want := nn.Predict(x), ref.predict(x)
+		for j := range want {
+			if math.Abs(got[j]-want[j]) > 1e-12 {
+				t.Fatalf("input %d output %d: got %v, want %v", i, j, got[j], want[j])
+			}
+		}
+	}
+}
+
+func TestTrainSampleStats(t *testing.T) {
+	inputs, targets := testData(20, 3, 2)
+	fused := testNet([]int{3, 8, 2})
+	// Hogwild takes the general path, which is deterministic with a single worker
+	general := testNet([]int{3, 8, 2}, Hogwild(true))
+	fused.Train(2, inputs, targets, func(int) bool { return true })
+	general.Train(2, inputs, targets, func(int) bool { return true })
+
+	for epoch := range 2 {
+		got, want := fused.EpochStats(epoch), general.EpochStats(epoch)
+		if got.GradNorm == 0 || math.Abs(got.GradNorm-want.GradNorm) > 1e-12 || math.Abs(got.TrainLoss-want.TrainLoss) > 1e-12 {
+			t.Fatalf("epoch %d: got %+v, want %+v", epoch, got, want)
+		}
+	}
+}
+
+func TestWorkersDeterministic(t *testing.T) {
+	inputs, targets := testData(40, 3, 2)
+	train := func(workers int) *Network {
+		nn := testNet([]int{3, 8, 5, 2}, BatchSize(10), Workers(workers))
+		nn.Train(3, inputs, targets, func(int) bool { return true })
+		return nn
+	}
+
+	// shard gradients are reduced in worker order, the same worker count gives the same weights
+	want := train(3)
+	for range 3 {
+		got := train(3)
+		for l, layer := range want.layers {
+			if !slices.Equal(got.layers[l].w, layer.w) || !slices.Equal(got.layers[l].b, layer.b) {
+				t.Fatalf("layer %d differs between runs", l)
+			}
+		}
+	}
+
+	// other worker counts only sum the gradients in another order
+	single := train(1)
+	for l, layer := range want.layers {
+		for _, v := range [][2][]float64{{single.layers[l].w, layer.w}, {single.layers[l].b, layer.b}} {
+			for i := range v[1] {
+				if math.Abs(v[0][i]-v[1][i]) > 1e-9 {
+					t.Fatalf("layer %d: 1 worker gives %v, 3 workers %v", l, v[0][i], v[1][i])
+				}
+			}
+		}
+	}
+}
+
+func TestBatchSizeInvalid(t *testing.T) {
+	for _, v := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("BatchSize(%d) didn't panic", v)
+				}
+			}()
+			BatchSize(v)
+		}()
+	}
+}
+
+var benchShapes = []int{784, 128, 10}
+
+func BenchmarkPredictScalar(b *testing.B) {
+	ref := newScalarNet(testNet(benchShapes))
+	inputs, _ := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	for i := range b.N {
+		ref.predict(inputs[i%len(inputs)])
+	}
+}
+
+func BenchmarkPredict(b *testing.B) {
+	nn := testNet(benchShapes)
+	inputs, _ := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	for i := range b.N {
+		nn.Predict(inputs[i%len(inputs)])
+	}
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	nn := testNet(benchShapes)
+	inputs, _ := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	for range b.N {
+		nn.PredictBatch(inputs)
+	}
+}
+
+// Train benchmarks run one epoch of 64 samples per iteration.
+
+func BenchmarkTrainScalar(b *testing.B) {
+	ref := newScalarNet(testNet(benchShapes))
+	inputs, targets := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	for range b.N {
+		for i := range inputs {
+			ref.backward(inputs[i], targets[i])
+		}
+	}
+}
+
+func BenchmarkTrain(b *testing.B) {
+	nn := testNet(benchShapes)
+	inputs, targets := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	nn.Train(b.N, inputs, targets, func(int) bool { return true })
+}
+
+func BenchmarkTrainBatch32(b *testing.B) {
+	nn := testNet(benchShapes, BatchSize(32))
+	inputs, targets := testData(64, benchShapes[0], benchShapes[2])
+	b.ResetTimer()
+	nn.Train(b.N, inputs, targets, func(int) bool { return true })
+}
@@ -1,94 +1,347 @@
 package feedforward
 
 import (
+	"bufio"
 	"encoding/gob"
 	"fmt"
+	"github.com/lnashier/gonet/dataset"
 	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/metrics"
 	"github.com/lnashier/gonet/stats"
 	"io"
+	"maps"
 	"math/rand"
+	"slices"
+	"sync"
 	"time"
 )
 
+// Node and Layer describe the saved form of a network.
+// Weights are always saved as float64, whatever the precision of the network.
 type Node struct {
 	Weights []float64
 	Bias    float64
+	// Sparse replaces Weights for nodes of pruned layers.
+	Sparse *Sparse
+}
+
+// Sparse holds the weights of a node that survived pruning and their positions among Len weights.
+type Sparse struct {
+	Len     int
+	Indices []int32
+	Values  []float64
 }
 
 type Layer struct {
 	Nodes []*Node
 }
 
-type Network struct {
-	shapes []int
-	layers []*Layer
-	stats  *stats.Training
-	af     func(float64) float64
-	fd     func(float64) float64
-	lr     float64
+// Net is a feedforward network computing in precision T.
+// Activation functions are defined on float64 and applied to T values.
+// Only Network, the float64 network, implements gonet.Network and works with the help package:
+// a Net[float32] is trained with its own Train and TrainDataset, or converted, see Convert.
+// Save and SaveJSON write float64 values whatever T, LoadNet converts them back.
+//
+// A network is trained by one goroutine at a time. Predict, Save, SaveJSON, Shapes,
+// Sparsity, Snapshot and the statistics may be called from other goroutines meanwhile:
+// they see the weights between two mini-batch updates, never during one.
+// In Hogwild mode that holds for whole mini-batches, their workers racing with each other only.
+// Slices returned by Weights and Biases are shared and must not be used while training.
+// Snapshot returns an immutable copy that predicts without any locking.
+type Net[T fns.Float] struct {
+	mu        sync.RWMutex // guards weights and shapes against concurrent updates
+	shapes    []int
+	layers    []*dense[T]
+	statsMu   sync.Mutex // guards stats
+	stats     *stats.Training
+	af        func(float64) float64
+	fd        func(float64) float64
+	afName    string
+	lr        float64
+	schedule  func(epoch int) float64
+	batchSize int
+	workers   int
+	hogwild   bool
+	pruning   func(epoch int) float64
+	metadata  map[string]string
+	loss      func(predictions, targets [][]float64) float64
+	metrics   map[string]metrics.Metric
+	vInputs   [][]float64
+	vTargets  [][]float64
+	epoch     *epochStats     // of the epoch being trained, nil otherwise
+	ws        []*workspace[T] // one per worker
+	pool      sync.Pool       // prediction scratch buffers
+}
+
+// Network is the float64 network.
+type Network = Net[float64]
+
+// dense is a fully connected layer.
+// Weights are stored row-major as an out x in matrix, one row per node.
+type dense[T fns.Float] struct {
+	in, out int
+	w       []T
+	b       []T
+	pruned  []bool // nil unless weights were pruned
 }
 
 func Load(src io.Reader, opt ...NetworkOpt) (*Network, error) {
+	return LoadNet[float64](src, opt...)
+}
+
+// LoadNet loads a saved network with precision T, converting weights as needed.
+// The format, gob as written by Save or JSON as written by SaveJSON, is detected from the content.
+func LoadNet[T fns.Float](src io.Reader, opt ...NetworkOpt) (*Net[T], error) {
+	r := bufio.NewReader(src)
+	if isJSON(r) {
+		return loadJSON[T](r, opt)
+	}
+
 	var layers []*Layer
-	if err := gob.NewDecoder(src).Decode(&layers); err != nil {
+	if err := gob.NewDecoder(r).Decode(&layers); err != nil {
 		return nil, err
 	}
 
 	opts := defaultNetworkOpts
 	opts.apply(opt)
 
-	nn := &Network{layers: layers}
-	nn.shapes = make([]int, len(nn.layers)+1)
-	nn.shapes[0] = len(nn.layers[0].Nodes[0].Weights)
-	for i, layer := range nn.layers {
+	if len(layers) == 0 || len(layers[0].Nodes) == 0 {
+		return nil, fmt.Errorf("model has no layers")
+	}
+	nn := &Net[T]{}
+	nn.shapes = make([]int, len(layers)+1)
+	nn.shapes[0] = len(layers[0].Nodes[0].Weights)
+	if sparse := layers[0].Nodes[0].Sparse; sparse != nil {
+		nn.shapes[0] = sparse.Len
+	}
+	for i, layer := range layers {
 		nn.shapes[i+1] = len(layer.Nodes)
 	}
+	if slices.Min(nn.shapes) < 1 {
+		return nil, fmt.Errorf("model has invalid shapes %v", nn.shapes)
+	}
+
+	nn.layers = make([]*dense[T], len(layers))
+	for i, layer := range layers {
+		d := newDense[T](nn.shapes[i], nn.shapes[i+1])
+		for j, node := range layer.Nodes {
+			row := d.w[j*d.in : (j+1)*d.in]
+			if node.Sparse == nil {
+				if len(node.Weights) != d.in {
+					return nil, fmt.Errorf("layer %d: node %d has %d weights, want %d", i, j, len(node.Weights), d.in)
+				}
+				convert(row, node.Weights)
+			} else {
+				sparse := node.Sparse
+				if sparse.Len != d.in || len(sparse.Indices) != len(sparse.Values) {
+					return nil, fmt.Errorf("layer %d: node %d has %d of %d sparse weights, want %d", i, j, len(sparse.Values), sparse.Len, d.in)
+				}
+				if d.pruned == nil {
+					d.pruned = make([]bool, len(d.w))
+				}
+				pruned := d.pruned[j*d.in : (j+1)*d.in]
+				for i := range pruned {
+					pruned[i] = true
+				}
+				for k, index := range sparse.Indices {
+					if index < 0 || int(index) >= d.in {
+						return nil, fmt.Errorf("layer %d: node %d has sparse weight %d out of range", i, j, index)
+					}
+					row[index] = T(sparse.Values[k])
+					pruned[index] = false
+				}
+			}
+			d.b[j] = T(node.Bias)
+		}
+		nn.layers[i] = d
+	}
+
 	// prediction
-	nn.af = opts.activation
 	// network may be retrained (resume training)
-	nn.fd = opts.activationDerivative
-	nn.lr = opts.learningRate
+	nn.configure(opts)
 
 	return nn, nil
 }
 
 func New(opt ...NetworkOpt) *Network {
+	return NewNet[float64](opt...)
+}
+
+// NewNet constructs a randomly initialized network with precision T.
+func NewNet[T fns.Float](opt ...NetworkOpt) *Net[T] {
 	opts := defaultNetworkOpts
 	opts.apply(opt)
 
-	nn := &Network{shapes: opts.shapes}
+	nn := &Net[T]{shapes: opts.shapes}
 
 	// input, hidden(s), output
-	nn.layers = make([]*Layer, len(nn.shapes)-1)
+	nn.layers = make([]*dense[T], len(nn.shapes)-1)
 
 	for i := 1; i < len(nn.shapes); i++ {
-		layer := &Layer{
-			Nodes: make([]*Node, nn.shapes[i]),
+		d := newDense[T](nn.shapes[i-1], nn.shapes[i])
+		for j := range d.out {
+			convert(d.w[j*d.in:(j+1)*d.in], fns.RandomVector(d.in))
+			d.b[j] = T(rand.Float64() - 0.5)
 		}
+		nn.layers[i-1] = d
+	}
 
-		for j := range layer.Nodes {
-			layer.Nodes[j] = &Node{
-				Weights: fns.RandomVector(nn.shapes[i-1]),
-				Bias:    rand.Float64() - 0.5,
-			}
+	nn.configure(opts)
+
+	return nn
+}
+
+// FromWeights constructs a network around existing weights and biases, one matrix per layer
+// as described by Weights and Biases. The slices are used as is, not copied.
+func FromWeights[T fns.Float](weights, biases [][]T, opt ...NetworkOpt) (*Net[T], error) {
+	opts := defaultNetworkOpts
+	opts.apply(opt)
+
+	if len(weights) == 0 || len(weights) != len(biases) {
+		return nil, fmt.Errorf("got %d weight matrices and %d bias vectors", len(weights), len(biases))
+	}
+
+	nn := &Net[T]{shapes: make([]int, len(weights)+1)}
+	for l, w := range weights {
+		out := len(biases[l])
+		if out == 0 || len(w)%out != 0 {
+			return nil, fmt.Errorf("layer %d: %d weights don't fit %d nodes", l, len(w), out)
+		}
+		in := len(w) / out
+		if l > 0 && in != nn.shapes[l] {
+			return nil, fmt.Errorf("layer %d: %d inputs, previous layer has %d nodes", l, in, nn.shapes[l])
 		}
+		nn.shapes[l], nn.shapes[l+1] = in, out
+		nn.layers = append(nn.layers, &dense[T]{in: in, out: out, w: w, b: biases[l]})
+	}
+
+	nn.configure(opts)
 
-		nn.layers[i-1] = layer
+	return nn, nil
+}
+
+// Convert returns a copy of the network with precision U.
+// Training statistics are not carried over.
+func Convert[U, T fns.Float](nn *Net[T]) *Net[U] {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	c := &Net[U]{
+		shapes:    append([]int(nil), nn.shapes...),
+		layers:    make([]*dense[U], len(nn.layers)),
+		af:        nn.af,
+		fd:        nn.fd,
+		afName:    nn.afName,
+		lr:        nn.lr,
+		schedule:  nn.schedule,
+		batchSize: nn.batchSize,
+		workers:   nn.workers,
+		hogwild:   nn.hogwild,
+		pruning:   nn.pruning,
+		metadata:  maps.Clone(nn.metadata),
+		loss:      nn.loss,
+		metrics:   nn.metrics,
+		vInputs:   nn.vInputs,
+		vTargets:  nn.vTargets,
 	}
+	for i, d := range nn.layers {
+		cd := newDense[U](d.in, d.out)
+		convert(cd.w, d.w)
+		convert(cd.b, d.b)
+		cd.pruned = slices.Clone(d.pruned)
+		c.layers[i] = cd
+	}
+	return c
+}
 
+func (nn *Net[T]) configure(opts networkOpts) {
 	nn.af = opts.activation
 	nn.fd = opts.activationDerivative
+	nn.afName = opts.activationName
 	nn.lr = opts.learningRate
+	nn.schedule = opts.learningRateSchedule
+	nn.batchSize = max(opts.batchSize, 1)
+	nn.workers = max(opts.workers, 1)
+	nn.hogwild = opts.hogwild
+	nn.pruning = opts.pruning
+	nn.metadata = opts.metadata
+	nn.loss = opts.loss
+	nn.metrics = opts.metrics
+	nn.vInputs = opts.validationInputs
+	nn.vTargets = opts.validationTargets
+}
 
-	return nn
+func newDense[T fns.Float](in, out int) *dense[T] {
+	return &dense[T]{
+		in:  in,
+		out: out,
+		w:   make([]T, out*in),
+		b:   make([]T, out),
+	}
+}
+
+// Train panics if an input or target doesn't fit the network, like Predict.
+func (nn *Net[T]) Train(epochs int, inputs, targets [][]T, callback func(int) bool) {
+	checkBatch(nn.shapes, inputs, targets)
+	nn.train(epochs, callback, func(batch func(inputs, targets [][]T)) error {
+		for start := 0; start < len(inputs); start += nn.batchSize {
+			end := min(start+nn.batchSize, len(inputs))
+			batch(inputs[start:end], targets[start:end])
+		}
+		return nil
+	})
+}
+
+// TrainDataset trains the network like Train, reading the examples of every epoch from ds.
+// Training stops at the first error of the dataset, which is returned.
+// It panics on an example that doesn't fit the network, like Train.
+func (nn *Net[T]) TrainDataset(epochs int, ds dataset.Dataset, callback func(int) bool) error {
+	var inputs, targets [][]float64
+	var inputsT, targetsT [][]T
+	return nn.train(epochs, callback, func(batch func(inputs, targets [][]T)) error {
+		it := ds.Iter()
+		defer it.Close()
+		for {
+			inputs, targets = dataset.NextBatch(it, nn.batchSize, inputs[:0], targets[:0])
+			if len(inputs) == 0 {
+				return it.Err()
+			}
+			inputsT, targetsT = precision(inputsT[:0], inputs), precision(targetsT[:0], targets)
+			checkBatch(nn.shapes, inputsT, targetsT)
+			batch(inputsT, targetsT)
+		}
+	})
+}
+
+// precision appends the rows of src to dst, converted to T unless already float64.
+func precision[T fns.Float](dst [][]T, src [][]float64) [][]T {
+	if rows, ok := any(src).([][]T); ok {
+		return append(dst, rows...)
+	}
+	for _, row := range src {
+		dst = append(dst, fns.Convert[T](row))
+	}
+	return dst
 }
 
-func (nn *Network) Train(epochs int, inputs, targets [][]float64, callback func(int) bool) {
-	nn.stats = &stats.Training{
+// train runs up to the given number of epochs, each passing mini-batches to the batch function.
+// It returns the first error of a pass.
+func (nn *Net[T]) train(epochs int, callback func(int) bool, pass func(batch func(inputs, targets [][]T)) error) error {
+	training := &stats.Training{
 		Start: time.Now(),
 	}
+	nn.statsMu.Lock()
+	nn.stats = training
+	nn.statsMu.Unlock()
 	defer func() {
-		nn.stats.End = time.Now()
+		nn.statsMu.Lock()
+		training.End = time.Now()
+		nn.statsMu.Unlock()
+	}()
+
+	e := &epochStats{keep: len(nn.metrics) > 0}
+	defer func() {
+		nn.epoch = nil
 	}()
 
 	for epoch := range epochs {
@@ -96,23 +349,50 @@ func (nn *Network) Train(epochs int, inputs, targets [][]float64, callback func(
 			ID:    epoch,
 			Start: time.Now(),
 		}
-		nn.stats.Epochs.Store(epoch, epochStat)
-		for i, input := range inputs {
-			epochStat.Inputs++
-			nn.backward(input, targets[i])
+		nn.statsMu.Lock()
+		training.Add(epochStat)
+		nn.statsMu.Unlock()
+		if nn.schedule != nil {
+			nn.mu.Lock()
+			nn.lr = nn.schedule(epoch)
+			nn.mu.Unlock()
+		}
+		e.reset()
+		nn.epoch = e
+		err := pass(func(inputs, targets [][]T) {
+			nn.trainBatch(inputs, targets)
+			nn.statsMu.Lock()
+			epochStat.Inputs += len(inputs)
+			nn.statsMu.Unlock()
+		})
+		if err != nil {
+			return err
+		}
+		if nn.pruning != nil {
+			nn.PruneGlobal(nn.pruning(epoch))
 		}
-		epochStat.End = time.Now()
+		summary := nn.summarize()
+		nn.statsMu.Lock()
+		summary.ID, summary.Start, summary.Inputs = epochStat.ID, epochStat.Start, epochStat.Inputs
+		summary.End = time.Now()
+		*epochStat = summary
+		nn.statsMu.Unlock()
 		if !callback(epoch) {
 			break
 		}
 	}
+	return nil
 }
 
-func (nn *Network) String() string {
+func (nn *Net[T]) String() string {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
 	return fmt.Sprintf("Shapes: %v\nHidden Layers: %d\n", nn.shapes, len(nn.layers)-1)
 }
 
-func (nn *Network) TrainingDuration() time.Duration {
+func (nn *Net[T]) TrainingDuration() time.Duration {
+	nn.statsMu.Lock()
+	defer nn.statsMu.Unlock()
 	if nn.stats == nil || nn.stats.Start.IsZero() {
 		return -1
 	}
@@ -122,104 +402,359 @@ func (nn *Network) TrainingDuration() time.Duration {
 	return nn.stats.End.Sub(nn.stats.Start)
 }
 
-func (nn *Network) EpochStats(epoch int) stats.Epoch {
+func (nn *Net[T]) EpochStats(epoch int) stats.Epoch {
+	nn.statsMu.Lock()
+	defer nn.statsMu.Unlock()
 	if nn.stats == nil {
 		return stats.Epoch{}
 	}
-	epochStats, ok := nn.stats.Epochs.Load(epoch)
-	if ok {
-		return *epochStats.(*stats.Epoch)
+	if e, ok := nn.stats.Epoch(epoch); ok {
+		return *e
 	}
 	return stats.Epoch{}
 }
 
-func (nn *Network) Predict(input []float64) []float64 {
-	activation := input
-	for l := range len(nn.layers) {
-		activation = nn.forward(activation, l)
+// Epochs calls yield with the statistics of every epoch of the last training in order, until it returns false.
+func (nn *Net[T]) Epochs(yield func(stats.Epoch) bool) {
+	if history := nn.History(); history != nil {
+		history.All(yield)
+	}
+}
+
+// History returns a copy of the statistics of the last training, nil if the network was not trained.
+func (nn *Net[T]) History() *stats.Training {
+	nn.statsMu.Lock()
+	defer nn.statsMu.Unlock()
+	if nn.stats == nil {
+		return nil
+	}
+	return nn.stats.Clone()
+}
+
+// Shapes returns the number of nodes in each layer, starting with the input layer.
+func (nn *Net[T]) Shapes() []int {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	return append([]int(nil), nn.shapes...)
+}
+
+// Weights returns the weights of layer l, 0 being the first hidden layer,
+// as a row-major matrix with one row of shapes[l] weights per node.
+// The slice is shared with the network.
+func (nn *Net[T]) Weights(l int) []T {
+	return nn.layers[l].w
+}
+
+// Biases returns the biases of layer l, one per node.
+// The slice is shared with the network.
+func (nn *Net[T]) Biases(l int) []T {
+	return nn.layers[l].b
+}
+
+// SetWeights replaces the weights and biases of layer l, laid out like Weights and Biases.
+// Pruned weights stay zero.
+func (nn *Net[T]) SetWeights(l int, weights, biases []T) error {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	if l < 0 || l >= len(nn.layers) {
+		return fmt.Errorf("network has no layer %d", l)
+	}
+	layer := nn.layers[l]
+	if len(weights) != len(layer.w) || len(biases) != len(layer.b) {
+		return fmt.Errorf("layer %d: got %d weights and %d biases, want %d and %d", l, len(weights), len(biases), len(layer.w), len(layer.b))
 	}
-	return activation
+	copy(layer.w, weights)
+	copy(layer.b, biases)
+	layer.mask()
+	return nil
 }
 
-func (nn *Network) Save(w io.Writer) error {
-	layers := nn.layers
+// Activation returns the activation function of the network.
+func (nn *Net[T]) Activation() func(float64) float64 {
+	return nn.af
+}
+
+// ActivationName returns the name of the activation function set with NamedActivation, if any.
+func (nn *Net[T]) ActivationName() string {
+	return nn.afName
+}
+
+// Metadata returns the metadata of the network, saved by SaveJSON.
+func (nn *Net[T]) Metadata() map[string]string {
+	return nn.metadata
+}
+
+func (nn *Net[T]) Predict(input []T) []T {
+	return nn.PredictInto(nil, input)
+}
+
+func (nn *Net[T]) Save(w io.Writer) error {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	layers := make([]*Layer, len(nn.layers))
+	for i, d := range nn.layers {
+		layer := &Layer{Nodes: make([]*Node, d.out)}
+		for j := range layer.Nodes {
+			row := d.w[j*d.in : (j+1)*d.in]
+			node := &Node{Bias: float64(d.b[j])}
+			if d.pruned == nil {
+				node.Weights = make([]float64, d.in)
+				convert(node.Weights, row)
+			} else {
+				node.Sparse = &Sparse{Len: d.in}
+				for i, pruned := range d.pruned[j*d.in : (j+1)*d.in] {
+					if !pruned {
+						node.Sparse.Indices = append(node.Sparse.Indices, int32(i))
+						node.Sparse.Values = append(node.Sparse.Values, float64(row[i]))
+					}
+				}
+			}
+			layer.Nodes[j] = node
+		}
+		layers[i] = layer
+	}
 	return gob.NewEncoder(w).Encode(layers)
 }
 
-func (nn *Network) forward(prevActivation []float64, atLayer int) []float64 {
-	layer := nn.layers[atLayer]
-	activation := make([]float64, len(layer.Nodes))
-	for i, node := range layer.Nodes {
-		sum := node.Bias
-		for j := range prevActivation {
-			sum += prevActivation[j] * node.Weights[j]
+// forward computes activations a (n x out) of the layer for a batch x (n x in).
+func (d *dense[T]) forward(n int, x, a []T, af func(float64) float64) {
+	a = a[:n*d.out]
+	for i := range n {
+		copy(a[i*d.out:(i+1)*d.out], d.b)
+	}
+	gemmNT(n, d.out, d.in, x, d.w, a)
+	for i, v := range a {
+		a[i] = T(af(float64(v)))
+	}
+}
+
+// workspace holds the buffers of a mini-batch pass, sized for the largest batch.
+type workspace[T fns.Float] struct {
+	size int
+	x    []T   // inputs, batch x shapes[0]
+	t    []T   // targets, batch x shapes[len(shapes)-1]
+	a    [][]T // activations per layer, batch x out
+	d    [][]T // deltas per layer, batch x out
+	gw   [][]T // weight gradients per layer, out x in
+	gb   [][]T // bias gradients per layer, out
+}
+
+func newWorkspace[T fns.Float](shapes []int, size int) *workspace[T] {
+	ws := &workspace[T]{
+		size: size,
+		x:    make([]T, size*shapes[0]),
+		t:    make([]T, size*shapes[len(shapes)-1]),
+	}
+	for i := 1; i < len(shapes); i++ {
+		ws.a = append(ws.a, make([]T, size*shapes[i]))
+		ws.d = append(ws.d, make([]T, size*shapes[i]))
+		ws.gw = append(ws.gw, make([]T, shapes[i]*shapes[i-1]))
+		ws.gb = append(ws.gb, make([]T, shapes[i]))
+	}
+	return ws
+}
+
+// checkBatch panics if an input or target doesn't fit the network, rather than training on part of it
+// or on the values of a previous batch.
+func checkBatch[T fns.Float](shapes []int, inputs, targets [][]T) {
+	if len(inputs) != len(targets) {
+		panic(fmt.Sprintf("%d inputs, %d targets", len(inputs), len(targets)))
+	}
+	out := shapes[len(shapes)-1]
+	for i := range inputs {
+		checkInput(shapes, inputs[i])
+		if len(targets[i]) != out {
+			panic(fmt.Sprintf("target of %d values, network has %d outputs", len(targets[i]), out))
 		}
-		activation[i] = nn.af(sum)
 	}
-	return activation
 }
 
-func (nn *Network) backward(input []float64, target []float64) {
-	activations := make([][]float64, len(nn.layers))
-	activation := input
-	for l := range len(nn.layers) {
-		activation = nn.forward(activation, l)
-		activations[l] = activation
+// load packs a batch of inputs and targets into contiguous rows.
+func (ws *workspace[T]) load(inputs, targets [][]T) {
+	in := len(ws.x) / ws.size
+	out := len(ws.t) / ws.size
+	for i := range inputs {
+		copy(ws.x[i*in:(i+1)*in], inputs[i])
+		copy(ws.t[i*out:(i+1)*out], targets[i])
 	}
+}
 
-	deltas := make([][]float64, len(nn.layers))
+// trainBatch splits the batch into one shard per worker and computes the shard gradients in parallel.
+// Shard gradients are summed in worker order, so a given worker count always yields the same update.
+// In Hogwild mode every worker applies its own gradients as soon as they are ready, without any locking
+// between workers, the network being locked for the whole batch instead.
+func (nn *Net[T]) trainBatch(inputs, targets [][]T) {
+	if nn.hogwild {
+		nn.mu.Lock()
+		defer nn.mu.Unlock()
+	}
 
-	// deltas
-	for l := len(nn.layers) - 1; l >= 0; l-- {
-		currLayer := nn.layers[l]
-		currActivation := activations[l]
-		var nextLayer *Layer
-		if l+1 < len(nn.layers) {
-			nextLayer = nn.layers[l+1]
-		}
-
-		deltas[l] = make([]float64, len(currLayer.Nodes))
-
-		for i := range deltas[l] {
-			err := 0.0
-			if nextLayer != nil {
-				// current is a hidden layer
-				for j, node := range nextLayer.Nodes {
-					err += deltas[l+1][j] * node.Weights[i]
-				}
-			} else {
-				// current is the output layer
-				err = target[i] - currActivation[i]
-			}
-			deltas[l][i] = err * nn.fd(currActivation[i])
+	n := len(inputs)
+	shards := min(nn.workers, n)
+
+	if n == 1 && !nn.hogwild {
+		nn.trainSample(inputs, targets)
+		return
+	}
+
+	if shards == 1 {
+		nn.shard(nn.workspace(0, n), inputs, targets)
+	} else {
+		var wg sync.WaitGroup
+		for w := range shards {
+			lo, hi := w*n/shards, (w+1)*n/shards
+			ws := nn.workspace(w, hi-lo)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				nn.shard(ws, inputs[lo:hi], targets[lo:hi])
+			}()
 		}
+		wg.Wait()
 	}
 
-	// update
-	for l := len(nn.layers) - 1; l >= 0; l-- {
-		var prevLayer *Layer
-		var prevActivation []float64
-		if l-1 >= 0 {
-			prevLayer = nn.layers[l-1]
-			prevActivation = activations[l-1]
+	nn.track(shards, n)
+
+	if nn.hogwild {
+		nn.trackGradient(shards, n)
+		return
+	}
+
+	ws := nn.ws[0]
+	for w := 1; w < shards; w++ {
+		for l := range nn.layers {
+			axpy(1, nn.ws[w].gw[l], ws.gw[l])
+			axpy(1, nn.ws[w].gb[l], ws.gb[l])
 		}
-		currLayer := nn.layers[l]
+	}
+	nn.trackGradient(1, n)
+	nn.mu.Lock()
+	nn.update(ws, n)
+	nn.mu.Unlock()
+}
 
-		for i, currNode := range currLayer.Nodes {
-			delta := deltas[l][i]
-			currNode.Bias = nn.lr * delta
+// shard computes the gradients of a shard of the batch in its workspace, applying them in Hogwild mode.
+func (nn *Net[T]) shard(ws *workspace[T], inputs, targets [][]T) {
+	ws.load(inputs, targets)
+	nn.backward(ws, len(inputs))
+	if nn.hogwild {
+		nn.update(ws, len(inputs))
+	}
+}
 
-			if prevLayer != nil {
-				// current layer represents either the output layer or a hidden layer beyond the first
-				for j, _ := range prevLayer.Nodes {
-					currNode.Weights[j] += nn.lr * prevActivation[j] * delta
-				}
-			} else {
-				// current layer is the first hidden layer
-				for j := range len(input) {
-					currNode.Weights[j] += nn.lr * input[j] * delta
-				}
+// workspace returns the buffers of worker w, large enough for a shard of the given size.
+func (nn *Net[T]) workspace(w, size int) *workspace[T] {
+	if len(nn.ws) != nn.workers {
+		nn.ws = make([]*workspace[T], nn.workers)
+	}
+	if nn.ws[w] == nil || nn.ws[w].size < size {
+		nn.ws[w] = newWorkspace[T](nn.shapes, size)
+	}
+	return nn.ws[w]
+}
+
+// trainSample trains on a batch of a single sample. Its gradients are the outer products of the
+// layer deltas and inputs, applied to the weights directly instead of being written out first.
+func (nn *Net[T]) trainSample(inputs, targets [][]T) {
+	ws := nn.workspace(0, 1)
+	ws.load(inputs, targets)
+	nn.deltas(ws, 1)
+	nn.track(1, 1)
+
+	// squared gradient norm of a sample is the sum of |d|²·(|prev|²+1) over layers
+	var sum float64
+	rate := T(nn.lr)
+	nn.mu.Lock()
+	for l, layer := range nn.layers {
+		prev := ws.x
+		if l > 0 {
+			prev = ws.a[l-1]
+		}
+		prev = prev[:layer.in]
+		d := ws.d[l][:layer.out]
+		if nn.epoch != nil {
+			sum += float64(dot(0, d, d)) * float64(dot(1, prev, prev))
+		}
+		for i, v := range d {
+			axpy(rate*v, prev, layer.w[i*layer.in:(i+1)*layer.in])
+		}
+		axpy(rate, d, layer.b)
+		layer.mask()
+	}
+	nn.mu.Unlock()
+	nn.trackNorm(sum, 1)
+}
+
+// backward runs the batch of n samples loaded in ws forward through the network
+// and accumulates the gradients of all layers in ws.
+func (nn *Net[T]) backward(ws *workspace[T], n int) {
+	nn.deltas(ws, n)
+	nn.gradients(ws, n)
+}
+
+// deltas runs the batch of n samples loaded in ws forward through the network
+// and propagates the output errors back, leaving the deltas of all layers in ws.
+func (nn *Net[T]) deltas(ws *workspace[T], n int) {
+	x := ws.x
+	for l, layer := range nn.layers {
+		layer.forward(n, x, ws.a[l], nn.af)
+		x = ws.a[l]
+	}
+
+	// deltas
+	last := len(nn.layers) - 1
+	for l := last; l >= 0; l-- {
+		layer := nn.layers[l]
+		a := ws.a[l][:n*layer.out]
+		d := ws.d[l][:n*layer.out]
+		if l == last {
+			// output layer
+			t := ws.t[:n*layer.out]
+			for i, v := range a {
+				d[i] = (t[i] - v) * T(nn.fd(float64(v)))
+			}
+		} else {
+			// hidden layer, propagate deltas of the next layer back through its weights
+			next := nn.layers[l+1]
+			clear(d)
+			gemmNN(n, layer.out, next.out, ws.d[l+1], next.w, d)
+			for i, v := range a {
+				d[i] *= T(nn.fd(float64(v)))
 			}
 		}
 	}
 }
+
+// gradients computes the gradients of all layers in ws from the deltas of n samples.
+func (nn *Net[T]) gradients(ws *workspace[T], n int) {
+	for l, layer := range nn.layers {
+		prev := ws.x
+		if l > 0 {
+			prev = ws.a[l-1]
+		}
+		d := ws.d[l][:n*layer.out]
+		clear(ws.gw[l])
+		gemmTN(layer.out, layer.in, n, d, prev, ws.gw[l])
+		clear(ws.gb[l])
+		for i := range n {
+			axpy(1, d[i*layer.out:(i+1)*layer.out], ws.gb[l])
+		}
+	}
+}
+
+// update applies the gradients accumulated in ws, averaged over n samples.
+func (nn *Net[T]) update(ws *workspace[T], n int) {
+	rate := T(nn.lr / float64(n))
+	for l, layer := range nn.layers {
+		axpy(rate, ws.gw[l], layer.w)
+		axpy(rate, ws.gb[l], layer.b)
+		layer.mask()
+	}
+}
+
+// convert copies src into dst, converting between precisions.
+func convert[T, U fns.Float](dst []T, src []U) {
+	for i, v := range src {
+		dst[i] = T(v)
+	}
+}
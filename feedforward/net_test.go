@@ -0,0 +1,121 @@
+package feedforward
+
+import (
+	"github.com/lnashier/gonet/dataset"
+	"github.com/lnashier/gonet/metrics"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestConcurrentAccess is meant for the race detector: go test -race.
+// The network is read from other goroutines while it trains, as documented on Net.
+func TestConcurrentAccess(t *testing.T) {
+	inputs, targets := testData(64, 4, 3)
+	nn := testNet([]int{4, 8, 3},
+		BatchSize(8),
+		Workers(2),
+		Metrics(map[string]metrics.Metric{"accuracy": metrics.Accuracy}),
+		Validation(inputs[:16], targets[:16]),
+	)
+
+	// trains until the readers are done, reading starts after the first epoch
+	var done atomic.Bool
+	started, trained := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(trained)
+		nn.Train(1<<20, inputs, targets, func(epoch int) bool {
+			if epoch == 0 {
+				close(started)
+			}
+			return !done.Load()
+		})
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	readers := map[string]func(){
+		"Predict": func() {
+			nn.Predict(inputs[0])
+			nn.PredictBatch(inputs[:4])
+		},
+		"Snapshot": func() {
+			nn.Snapshot().Predict(inputs[0])
+		},
+		"Save": func() {
+			if err := nn.Save(io.Discard); err != nil {
+				t.Error(err)
+			}
+			if err := nn.SaveJSON(io.Discard); err != nil {
+				t.Error(err)
+			}
+		},
+		"EpochStats": func() {
+			nn.EpochStats(0)
+			nn.History()
+			nn.TrainingDuration()
+		},
+		"String": func() {
+			_ = nn.String()
+			nn.Shapes()
+			nn.Sparsity()
+		},
+	}
+	for _, read := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				read()
+			}
+		}()
+	}
+
+	wg.Wait()
+	done.Store(true)
+	<-trained
+}
+
+func TestPredictInputSize(t *testing.T) {
+	nn := testNet([]int{3, 4, 2})
+	for _, input := range [][]float64{{1, 2}, {1, 2, 3, 4}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("input of %d values didn't panic", len(input))
+				}
+			}()
+			nn.PredictBatch([][]float64{{1, 2, 3}, input})
+		}()
+	}
+}
+
+func TestTrainInputSize(t *testing.T) {
+	nn := testNet([]int{3, 4, 2})
+	tests := map[string][2][][]float64{
+		"short input":  {{{1, 2, 3}, {1, 2}}, {{0, 1}, {1, 0}}},
+		"long input":   {{{1, 2, 3}, {1, 2, 3, 4}}, {{0, 1}, {1, 0}}},
+		"short target": {{{1, 2, 3}, {1, 2, 3}}, {{0, 1}, {1}}},
+		"long target":  {{{1, 2, 3}, {1, 2, 3}}, {{0, 1}, {1, 0, 1}}},
+		"targets":      {{{1, 2, 3}, {1, 2, 3}}, {{0, 1}}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("didn't panic")
+				}
+			}()
+			nn.Train(1, test[0], test[1], func(int) bool { return true })
+		})
+		t.Run(name+"/dataset", func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("didn't panic")
+				}
+			}()
+			nn.TrainDataset(1, &dataset.Slices{Inputs: test[0], Targets: test[1]}, func(int) bool { return true })
+		})
+	}
+}
@@ -1,16 +1,36 @@
 package feedforward
 
+import (
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/metrics"
+)
+
 type NetworkOpt func(*networkOpts)
 
 type networkOpts struct {
 	shapes               []int
 	learningRate         float64
+	learningRateSchedule func(epoch int) float64
 	activation           func(float64) float64
 	activationDerivative func(float64) float64
+	activationName       string
+	batchSize            int
+	workers              int
+	hogwild              bool
+	pruning              func(epoch int) float64
+	metadata             map[string]string
+	loss                 func(predictions, targets [][]float64) float64
+	metrics              map[string]metrics.Metric
+	validationInputs     [][]float64
+	validationTargets    [][]float64
 }
 
 var defaultNetworkOpts = networkOpts{
 	learningRate: 0.1,
+	batchSize:    1,
+	workers:      1,
+	loss:         fns.MeanSquaredError,
 }
 
 func (s *networkOpts) apply(opts []NetworkOpt) {
@@ -31,9 +51,17 @@ func LearningRate(v float64) NetworkOpt {
 	}
 }
 
+// LearningRateSchedule sets the learning rate of every epoch, see StepDecay and ExponentialDecay.
+func LearningRateSchedule(v func(epoch int) float64) NetworkOpt {
+	return func(s *networkOpts) {
+		s.learningRateSchedule = v
+	}
+}
+
 func Activation(v func(float64) float64) NetworkOpt {
 	return func(s *networkOpts) {
 		s.activation = v
+		s.activationName = ""
 	}
 }
 
@@ -42,3 +70,93 @@ func ActivationDerivative(v func(float64) float64) NetworkOpt {
 		s.activationDerivative = v
 	}
 }
+
+// NamedActivation sets a well known activation function and its derivative by name, see fns.Activation.
+// Unlike functions set with Activation, the name is recorded by formats that save activations.
+func NamedActivation(v string) NetworkOpt {
+	f, df, ok := fns.Activation(v)
+	if !ok {
+		panic(fmt.Sprintf("unknown activation: %s", v))
+	}
+	return func(s *networkOpts) {
+		s.activation = f
+		s.activationDerivative = df
+		s.activationName = v
+	}
+}
+
+// BatchSize sets the number of samples whose gradients are averaged into one update.
+// Defaults to 1, updating the network after every sample.
+func BatchSize(v int) NetworkOpt {
+	if v < 1 {
+		panic(fmt.Sprintf("invalid batch size: %d", v))
+	}
+	return func(s *networkOpts) {
+		s.batchSize = v
+	}
+}
+
+// Workers sets the number of goroutines that share the work of each mini-batch.
+// The batch is split into one shard per worker and the shard gradients are reduced
+// in a fixed order, so training stays deterministic for a given worker count.
+// Workers only parallelize within a batch, a batch never has more shards than samples,
+// so they need a BatchSize larger than 1 to have any effect.
+// Defaults to 1.
+func Workers(v int) NetworkOpt {
+	return func(s *networkOpts) {
+		s.workers = v
+	}
+}
+
+// Hogwild lets workers apply their shard gradients directly to the shared weights
+// as soon as they are computed, without locking against each other and without reducing
+// the shard gradients first. Batches still run one after another: every batch waits
+// for all its workers, and the network is locked against Predict and Save for the whole batch.
+// Updates are cheaper but racy by design and no longer deterministic.
+func Hogwild(v bool) NetworkOpt {
+	return func(s *networkOpts) {
+		s.hogwild = v
+	}
+}
+
+// PruningSchedule prunes the network while training.
+// After every epoch, weights with the smallest magnitudes across all layers are pruned
+// until the network reaches the sparsity returned by v for that epoch, see GradualSparsity.
+func PruningSchedule(v func(epoch int) float64) NetworkOpt {
+	return func(s *networkOpts) {
+		s.pruning = v
+	}
+}
+
+// Metadata attaches free-form key/value pairs to the network, e.g. a version or the training data set.
+// Metadata is saved by SaveJSON.
+func Metadata(v map[string]string) NetworkOpt {
+	return func(s *networkOpts) {
+		s.metadata = v
+	}
+}
+
+// Loss sets the loss reported in the statistics of every epoch. Defaults to mean squared error.
+// It does not change what the network minimizes.
+func Loss(v func(predictions, targets [][]float64) float64) NetworkOpt {
+	return func(s *networkOpts) {
+		s.loss = v
+	}
+}
+
+// Metrics sets metrics reported by name in the statistics of every epoch, e.g. metrics.Accuracy.
+// Predictions of the whole epoch are kept to compute them.
+func Metrics(v map[string]metrics.Metric) NetworkOpt {
+	return func(s *networkOpts) {
+		s.metrics = v
+	}
+}
+
+// Validation sets held-out data predicted at the end of every epoch,
+// its loss and metrics being reported in the statistics of the epoch.
+func Validation(inputs, targets [][]float64) NetworkOpt {
+	return func(s *networkOpts) {
+		s.validationInputs = inputs
+		s.validationTargets = targets
+	}
+}
@@ -0,0 +1,113 @@
+package feedforward
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"slices"
+	"sync"
+)
+
+// scratch holds the buffers of a prediction, reused through a sync.Pool.
+// Consecutive layers write their activations to a and b alternately.
+type scratch[T fns.Float] struct {
+	x, a, b []T
+}
+
+func getScratch[T fns.Float](pool *sync.Pool, inputs, activations int) *scratch[T] {
+	s, _ := pool.Get().(*scratch[T])
+	if s == nil {
+		s = &scratch[T]{}
+	}
+	s.x = grow(s.x, inputs)
+	s.a = grow(s.a, activations)
+	s.b = grow(s.b, activations)
+	return s
+}
+
+// grow returns a slice of length n, reusing the array of s if large enough.
+func grow[T any](s []T, n int) []T {
+	return slices.Grow(s[:0], n)[:n]
+}
+
+// predict runs the batch of n rows in x through the layers and writes the outputs to dst.
+func predict[T fns.Float](layers []*dense[T], af func(float64) float64, s *scratch[T], n int, x, dst []T) {
+	for l, layer := range layers {
+		a := s.a
+		if l == len(layers)-1 {
+			a = dst
+		}
+		layer.forward(n, x, a, af)
+		x = a
+		s.a, s.b = s.b, s.a
+	}
+}
+
+// widest returns the largest number of nodes of a layer, the input layer aside.
+func widest(shapes []int) int {
+	return slices.Max(shapes[1:])
+}
+
+// checkInput panics if input doesn't fit the input layer, rather than predicting from part of it.
+func checkInput[T fns.Float](shapes []int, input []T) {
+	if len(input) != shapes[0] {
+		panic(fmt.Sprintf("input of %d values, network has %d inputs", len(input), shapes[0]))
+	}
+}
+
+// predictInto is PredictInto of networks and snapshots.
+func predictInto[T fns.Float](layers []*dense[T], shapes []int, af func(float64) float64, pool *sync.Pool, dst, input []T) []T {
+	checkInput(shapes, input)
+	s := getScratch[T](pool, 0, widest(shapes))
+	defer pool.Put(s)
+	dst = grow(dst, shapes[len(shapes)-1])
+	predict(layers, af, s, 1, input, dst)
+	return dst
+}
+
+// predictBatch is PredictBatch of networks and snapshots.
+func predictBatch[T fns.Float](layers []*dense[T], shapes []int, af func(float64) float64, pool *sync.Pool, inputs [][]T) [][]T {
+	for _, input := range inputs {
+		checkInput(shapes, input)
+	}
+	n, in, out := len(inputs), shapes[0], shapes[len(shapes)-1]
+	s := getScratch[T](pool, n*in, n*widest(shapes))
+	defer pool.Put(s)
+	for i, input := range inputs {
+		copy(s.x[i*in:(i+1)*in], input)
+	}
+
+	outputs := make([][]T, n)
+	dst := make([]T, n*out)
+	predict(layers, af, s, n, s.x, dst)
+	for i := range outputs {
+		outputs[i] = dst[i*out : (i+1)*out : (i+1)*out]
+	}
+	return outputs
+}
+
+// PredictInto writes the outputs for input to dst, growing it if too small, and returns it.
+// Buffers are pooled, so with a large enough dst predicting doesn't allocate.
+// It panics if input doesn't have one value per input node, like PredictBatch.
+func (nn *Net[T]) PredictInto(dst, input []T) []T {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	return predictInto(nn.layers, nn.shapes, nn.af, &nn.pool, dst, input)
+}
+
+// PredictBatch returns the outputs for a batch of inputs, computed a layer at a time for all of them.
+// The outputs share one allocation.
+func (nn *Net[T]) PredictBatch(inputs [][]T) [][]T {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	return predictBatch(nn.layers, nn.shapes, nn.af, &nn.pool, inputs)
+}
+
+// PredictInto is Net.PredictInto without locking.
+func (s *Snapshot[T]) PredictInto(dst, input []T) []T {
+	return predictInto(s.layers, s.shapes, s.af, &s.pool, dst, input)
+}
+
+// PredictBatch is Net.PredictBatch without locking.
+func (s *Snapshot[T]) PredictBatch(inputs [][]T) [][]T {
+	return predictBatch(s.layers, s.shapes, s.af, &s.pool, inputs)
+}
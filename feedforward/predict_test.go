@@ -0,0 +1,60 @@
+//go:build !race
+
+// The race detector makes sync.Pool drop buffers at random, so allocations are only counted without it.
+
+package feedforward
+
+import (
+	"github.com/lnashier/gonet/metrics"
+	"testing"
+)
+
+func TestPredictIntoAllocs(t *testing.T) {
+	nn := testNet([]int{8, 16, 4})
+	inputs, _ := testData(1, 8, 4)
+	dst := make([]float64, 4)
+	nn.PredictInto(dst, inputs[0])
+	if allocs := testing.AllocsPerRun(100, func() {
+		nn.PredictInto(dst, inputs[0])
+	}); allocs != 0 {
+		t.Errorf("PredictInto allocated %v times", allocs)
+	}
+	s := nn.Snapshot()
+	if allocs := testing.AllocsPerRun(100, func() {
+		s.PredictInto(dst, inputs[0])
+	}); allocs != 0 {
+		t.Errorf("Snapshot.PredictInto allocated %v times", allocs)
+	}
+}
+
+func BenchmarkPredictInto(b *testing.B) {
+	nn := testNet(benchShapes)
+	inputs, _ := testData(64, benchShapes[0], benchShapes[2])
+	dst := make([]float64, benchShapes[2])
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		dst = nn.PredictInto(dst, inputs[i%len(inputs)])
+	}
+}
+
+// Statistics reuse their buffers from batch to batch, so an epoch allocates the same whatever its number of batches.
+func TestTrainAllocs(t *testing.T) {
+	for name, opt := range map[string][]NetworkOpt{
+		"loss":    nil,
+		"metrics": {Metrics(map[string]metrics.Metric{"accuracy": metrics.Accuracy})},
+	} {
+		allocs := func(n, epochs int) float64 {
+			nn := testNet([]int{8, 16, 4}, append(opt, BatchSize(4))...)
+			inputs, targets := testData(n, 8, 4)
+			return testing.AllocsPerRun(10, func() {
+				nn.Train(epochs, inputs, targets, func(int) bool { return true })
+			})
+		}
+		// one more epoch of 4 or of 64 batches
+		few, many := allocs(16, 3)-allocs(16, 2), allocs(256, 3)-allocs(256, 2)
+		if many != few {
+			t.Errorf("%s: an epoch of 64 batches allocated %v times, one of 4 batches %v times", name, many, few)
+		}
+	}
+}
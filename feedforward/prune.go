@@ -0,0 +1,217 @@
+package feedforward
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// PruneGlobal prunes the weights with the smallest magnitudes across all layers
+// until the given fraction of all weights is pruned.
+// Pruned weights are zero and stay zero during further training.
+func (nn *Net[T]) PruneGlobal(sparsity float64) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	nn.prune(nn.layers, sparsity)
+}
+
+// PruneLayers prunes the weights with the smallest magnitudes of every layer
+// until the given fraction of each layer's weights is pruned.
+func (nn *Net[T]) PruneLayers(sparsity float64) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	for _, layer := range nn.layers {
+		nn.prune([]*dense[T]{layer}, sparsity)
+	}
+}
+
+// prune ranks weights of the layers by magnitude, already pruned ones first,
+// and prunes the lowest ranked ones until the sparsity is reached.
+func (nn *Net[T]) prune(layers []*dense[T], sparsity float64) {
+	type weight struct {
+		layer     *dense[T]
+		index     int
+		magnitude float64
+	}
+
+	var weights []weight
+	for _, layer := range layers {
+		for i, w := range layer.w {
+			magnitude := math.Abs(float64(w))
+			if layer.pruned != nil && layer.pruned[i] {
+				magnitude = -1
+			}
+			weights = append(weights, weight{layer, i, magnitude})
+		}
+	}
+
+	n := int(math.Round(min(max(sparsity, 0), 1) * float64(len(weights))))
+	if n == 0 {
+		return
+	}
+	slices.SortStableFunc(weights, func(a, b weight) int {
+		return cmp.Compare(a.magnitude, b.magnitude)
+	})
+
+	for _, w := range weights[:n] {
+		if w.layer.pruned == nil {
+			w.layer.pruned = make([]bool, len(w.layer.w))
+		}
+		w.layer.pruned[w.index] = true
+	}
+	for _, layer := range layers {
+		layer.mask()
+	}
+}
+
+// PruneNodes removes the n nodes of hidden layer l, 0 being the first hidden layer,
+// whose outgoing weights have the smallest L2 norm. The network shrinks accordingly.
+func (nn *Net[T]) PruneNodes(l, n int) error {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	if l < 0 || l >= len(nn.layers)-1 {
+		return fmt.Errorf("layer %d is not a hidden layer", l)
+	}
+	layer, next := nn.layers[l], nn.layers[l+1]
+	if n < 0 || n >= layer.out {
+		return fmt.Errorf("can't remove %d of %d nodes", n, layer.out)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	norms := make([]float64, layer.out)
+	for k := range next.out {
+		for j, w := range next.w[k*next.in : (k+1)*next.in] {
+			norms[j] += float64(w * w)
+		}
+	}
+	nodes := make([]int, layer.out)
+	for j := range nodes {
+		nodes[j] = j
+	}
+	slices.SortStableFunc(nodes, func(a, b int) int {
+		return cmp.Compare(norms[a], norms[b])
+	})
+
+	nn.removeNodes(l, nodes[:n])
+	return nil
+}
+
+// RemoveNodes removes the given nodes of hidden layer l, along with their outgoing weights.
+func (nn *Net[T]) RemoveNodes(l int, nodes ...int) error {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	if l < 0 || l >= len(nn.layers)-1 {
+		return fmt.Errorf("layer %d is not a hidden layer", l)
+	}
+	layer := nn.layers[l]
+	removed := make(map[int]bool)
+	for _, j := range nodes {
+		if j < 0 || j >= layer.out {
+			return fmt.Errorf("layer %d has no node %d", l, j)
+		}
+		removed[j] = true
+	}
+	if len(removed) >= layer.out {
+		return fmt.Errorf("can't remove %d of %d nodes", len(removed), layer.out)
+	}
+	nn.removeNodes(l, nodes)
+	return nil
+}
+
+func (nn *Net[T]) removeNodes(l int, nodes []int) {
+	removed := make([]bool, nn.layers[l].out)
+	for _, j := range nodes {
+		removed[j] = true
+	}
+
+	layer, next := nn.layers[l], nn.layers[l+1]
+	nn.layers[l] = layer.keepRows(removed)
+	nn.layers[l+1] = next.keepCols(removed)
+	nn.shapes[l+1] = nn.layers[l].out
+	// shapes changed
+	nn.ws = nil
+}
+
+// Sparsity returns the fraction of pruned weights in the network.
+func (nn *Net[T]) Sparsity() float64 {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	pruned, total := 0, 0
+	for _, layer := range nn.layers {
+		total += len(layer.w)
+		for _, p := range layer.pruned {
+			if p {
+				pruned++
+			}
+		}
+	}
+	return float64(pruned) / float64(total)
+}
+
+// GradualSparsity returns a pruning schedule that raises sparsity from initial to final
+// between epochs begin and end, pruning quickly at first and slowly towards the end.
+// Sparsity is initial before begin and final from end on.
+// See "To prune, or not to prune" (Zhu and Gupta, 2017).
+func GradualSparsity(initial, final float64, begin, end int) func(epoch int) float64 {
+	return func(epoch int) float64 {
+		if epoch < begin {
+			return initial
+		}
+		if epoch >= end {
+			return final
+		}
+		progress := float64(epoch-begin) / float64(end-begin)
+		return final + (initial-final)*math.Pow(1-progress, 3)
+	}
+}
+
+// mask zeroes pruned weights.
+func (d *dense[T]) mask() {
+	for i, pruned := range d.pruned {
+		if pruned {
+			d.w[i] = 0
+		}
+	}
+}
+
+// keepRows returns a copy of the layer without the removed nodes.
+func (d *dense[T]) keepRows(removed []bool) *dense[T] {
+	c := &dense[T]{in: d.in}
+	for j := range d.out {
+		if removed[j] {
+			continue
+		}
+		c.out++
+		c.w = append(c.w, d.w[j*d.in:(j+1)*d.in]...)
+		c.b = append(c.b, d.b[j])
+		if d.pruned != nil {
+			c.pruned = append(c.pruned, d.pruned[j*d.in:(j+1)*d.in]...)
+		}
+	}
+	return c
+}
+
+// keepCols returns a copy of the layer without the weights of removed inputs.
+func (d *dense[T]) keepCols(removed []bool) *dense[T] {
+	c := &dense[T]{out: d.out, b: slices.Clone(d.b)}
+	for i := range d.in {
+		if !removed[i] {
+			c.in++
+		}
+	}
+	for j := range d.out {
+		for i := range d.in {
+			if removed[i] {
+				continue
+			}
+			c.w = append(c.w, d.w[j*d.in+i])
+			if d.pruned != nil {
+				c.pruned = append(c.pruned, d.pruned[j*d.in+i])
+			}
+		}
+	}
+	return c
+}
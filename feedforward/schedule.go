@@ -0,0 +1,17 @@
+package feedforward
+
+import "math"
+
+// StepDecay returns a learning rate schedule starting at lr and multiplied by gamma every step epochs.
+func StepDecay(lr, gamma float64, step int) func(epoch int) float64 {
+	return func(epoch int) float64 {
+		return lr * math.Pow(gamma, float64(epoch/max(step, 1)))
+	}
+}
+
+// ExponentialDecay returns a learning rate schedule starting at lr and multiplied by gamma every epoch.
+func ExponentialDecay(lr, gamma float64) func(epoch int) float64 {
+	return func(epoch int) float64 {
+		return lr * math.Pow(gamma, float64(epoch))
+	}
+}
@@ -0,0 +1,61 @@
+package feedforward
+
+import (
+	"github.com/lnashier/gonet/fns"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Snapshot is an immutable copy of a network's weights taken at one point of training.
+// It predicts without any locking and is safe for concurrent use.
+type Snapshot[T fns.Float] struct {
+	shapes   []int
+	layers   []*dense[T]
+	af       func(float64) float64
+	afName   string
+	metadata map[string]string
+	pool     sync.Pool
+}
+
+// Snapshot copies the network as it is between two mini-batch updates.
+func (nn *Net[T]) Snapshot() *Snapshot[T] {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	s := &Snapshot[T]{
+		shapes:   slices.Clone(nn.shapes),
+		layers:   make([]*dense[T], len(nn.layers)),
+		af:       nn.af,
+		afName:   nn.afName,
+		metadata: maps.Clone(nn.metadata),
+	}
+	for i, d := range nn.layers {
+		s.layers[i] = &dense[T]{
+			in:  d.in,
+			out: d.out,
+			w:   slices.Clone(d.w),
+			b:   slices.Clone(d.b),
+		}
+	}
+	return s
+}
+
+func (s *Snapshot[T]) Predict(input []T) []T {
+	return s.PredictInto(nil, input)
+}
+
+// Shapes returns the number of nodes in each layer, starting with the input layer.
+func (s *Snapshot[T]) Shapes() []int {
+	return slices.Clone(s.shapes)
+}
+
+// ActivationName returns the name of the activation function, if any.
+func (s *Snapshot[T]) ActivationName() string {
+	return s.afName
+}
+
+// Metadata returns the metadata of the network when the snapshot was taken.
+func (s *Snapshot[T]) Metadata() map[string]string {
+	return s.metadata
+}
@@ -0,0 +1,192 @@
+package feedforward
+
+import (
+	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/metrics"
+	"github.com/lnashier/gonet/stats"
+	"math"
+	"slices"
+)
+
+// validationBatch bounds the buffers of predicting validation data.
+const validationBatch = 256
+
+// epochStats accumulates the statistics of an epoch from its mini-batches.
+// Its buffers are reused from batch to batch and epoch to epoch.
+type epochStats struct {
+	loss     float64 // sum of batch losses, weighted by batch size
+	inputs   int
+	gradNorm float64 // sum of batch gradient norms
+	batches  int
+	// predictions and targets of the epoch one row after the other, kept for metrics
+	keep                 bool
+	predictions, targets []float64
+	// rows of the batch, and their buffer when neither kept nor float64
+	p, t [][]float64
+	buf  []float64
+}
+
+// reset starts a new epoch.
+func (e *epochStats) reset() {
+	*e = epochStats{
+		keep:        e.keep,
+		predictions: e.predictions[:0],
+		targets:     e.targets[:0],
+		p:           e.p[:0],
+		t:           e.t[:0],
+		buf:         e.buf,
+	}
+}
+
+// track records the outputs of a batch of n samples, split in shards, before they are applied.
+// Float64 outputs are read in place unless kept.
+func (nn *Net[T]) track(shards, n int) {
+	e := nn.epoch
+	if e == nil {
+		return
+	}
+	out := nn.shapes[len(nn.shapes)-1]
+	last := len(nn.layers) - 1
+
+	var p, t []float64
+	switch {
+	case e.keep:
+		start := len(e.predictions)
+		e.predictions = slices.Grow(e.predictions, n*out)[:start+n*out]
+		e.targets = slices.Grow(e.targets, n*out)[:start+n*out]
+		p, t = e.predictions[start:], e.targets[start:]
+	case !isFloat64[T]():
+		e.buf = grow(e.buf, 2*n*out)
+		p, t = e.buf[:n*out], e.buf[n*out:]
+	}
+	e.p, e.t = e.p[:0], e.t[:0]
+	for w := range shards {
+		lo, hi := w*n/shards, (w+1)*n/shards
+		a, y := nn.ws[w].a[last][:(hi-lo)*out], nn.ws[w].t[:(hi-lo)*out]
+		var sp, st []float64
+		if p != nil {
+			sp, st = p[lo*out:hi*out], t[lo*out:hi*out]
+			convert(sp, a)
+			convert(st, y)
+		} else {
+			sp, st = any(a).([]float64), any(y).([]float64)
+		}
+		for i := range hi - lo {
+			e.p = append(e.p, sp[i*out:(i+1)*out:(i+1)*out])
+			e.t = append(e.t, st[i*out:(i+1)*out:(i+1)*out])
+		}
+	}
+
+	e.loss += nn.loss(e.p, e.t) * float64(n)
+	e.inputs += n
+}
+
+func isFloat64[T fns.Float]() bool {
+	_, ok := any(T(0)).(float64)
+	return ok
+}
+
+// rows splits values into rows of n.
+func rows(values []float64, n int) [][]float64 {
+	r := make([][]float64, 0, len(values)/n)
+	for i := 0; i < len(values); i += n {
+		r = append(r, values[i:i+n:i+n])
+	}
+	return r
+}
+
+// trackGradient records the norm of the gradients of the first shards workspaces summed,
+// averaged over n samples like the update.
+func (nn *Net[T]) trackGradient(shards, n int) {
+	e := nn.epoch
+	if e == nil {
+		return
+	}
+	var sum float64
+	for l := range nn.layers {
+		if shards == 1 {
+			ws := nn.ws[0]
+			sum += float64(dot(0, ws.gw[l], ws.gw[l]) + dot(0, ws.gb[l], ws.gb[l]))
+			continue
+		}
+		sum += squaredSum(nn.ws[:shards], func(ws *workspace[T]) []T { return ws.gw[l] })
+		sum += squaredSum(nn.ws[:shards], func(ws *workspace[T]) []T { return ws.gb[l] })
+	}
+	nn.trackNorm(sum, n)
+}
+
+// trackNorm records a batch gradient of the given squared norm, averaged over n samples.
+func (nn *Net[T]) trackNorm(squared float64, n int) {
+	e := nn.epoch
+	if e == nil {
+		return
+	}
+	e.gradNorm += math.Sqrt(squared) / float64(n)
+	e.batches++
+}
+
+// squaredSum returns the squared norm of the sum of the gradients g of workspaces.
+func squaredSum[T fns.Float](workspaces []*workspace[T], g func(ws *workspace[T]) []T) float64 {
+	grads := make([][]T, len(workspaces))
+	for w, ws := range workspaces {
+		grads[w] = g(ws)
+	}
+	var sum float64
+	for i := range grads[0] {
+		var v float64
+		for _, grad := range grads {
+			v += float64(grad[i])
+		}
+		sum += v * v
+	}
+	return sum
+}
+
+// weightNorm returns the L2 norm of all weights and biases.
+func (nn *Net[T]) weightNorm() float64 {
+	var sum float64
+	for _, d := range nn.layers {
+		sum += float64(dot(0, d.w, d.w) + dot(0, d.b, d.b))
+	}
+	return math.Sqrt(sum)
+}
+
+// summarize returns the statistics of the epoch just trained, validating the network if set to.
+func (nn *Net[T]) summarize() stats.Epoch {
+	e := nn.epoch
+	summary := stats.Epoch{
+		LearningRate: nn.lr,
+		WeightNorm:   nn.weightNorm(),
+	}
+	if e.inputs > 0 {
+		summary.TrainLoss = e.loss / float64(e.inputs)
+		summary.GradNorm = e.gradNorm / float64(e.batches)
+	}
+	if e.keep && len(e.predictions) > 0 {
+		out := nn.shapes[len(nn.shapes)-1]
+		summary.Metrics = score(nn.metrics, rows(e.predictions, out), rows(e.targets, out))
+	}
+
+	if len(nn.vInputs) > 0 {
+		predictions := make([][]float64, 0, len(nn.vInputs))
+		for start := 0; start < len(nn.vInputs); start += validationBatch {
+			end := min(start+validationBatch, len(nn.vInputs))
+			outputs := nn.PredictBatch(precision[T](nil, nn.vInputs[start:end]))
+			predictions = append(predictions, fns.ConvertMat[float64](outputs)...)
+		}
+		summary.ValidationInputs = len(nn.vInputs)
+		summary.ValidationLoss = nn.loss(predictions, nn.vTargets)
+		if len(nn.metrics) > 0 {
+			summary.ValidationMetrics = score(nn.metrics, predictions, nn.vTargets)
+		}
+	}
+	return summary
+}
+
+func score(named map[string]metrics.Metric, predictions, targets [][]float64) map[string]float64 {
+	scores := make(map[string]float64, len(named))
+	for name, m := range named {
+		scores[name] = m(predictions, targets)
+	}
+	return scores
+}
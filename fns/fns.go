@@ -5,44 +5,77 @@ import (
 	"math/rand"
 )
 
-func Sigmoid(x float64) float64 {
+// Float is the set of floating-point types networks and helpers compute in.
+type Float interface {
+	~float32 | ~float64
+}
+
+func Sigmoid[T Float](x T) T {
 	// sigmoid(x) = 1 / (1 + exp(-x))
-	return 1 / (1 + math.Exp(-x))
+	return T(1 / (1 + math.Exp(-float64(x))))
 }
 
-func SigmoidDerivative(x float64) float64 {
+func SigmoidDerivative[T Float](x T) T {
 	// sigmoid'(j) = sigmoid(j) * (1 - sigmoid(j))
 	// here x = sigmoid(j)
 	return x * (1 - x)
 }
 
-func ReLU(x float64) float64 {
+func ReLU[T Float](x T) T {
 	if x > 0 {
 		return x
 	}
 	return 0
 }
 
-func ReLUDerivative(x float64) float64 {
+func ReLUDerivative[T Float](x T) T {
 	return ReLU(x)
 }
 
-func Tanh(x float64) float64 {
-	return math.Tanh(x)
+func Tanh[T Float](x T) T {
+	return T(math.Tanh(float64(x)))
 }
 
-func TanhDerivative(x float64) float64 {
+func TanhDerivative[T Float](x T) T {
 	// tanh'(j) = 1 - tanh^2(j)
 	// here x = math.Tanh(j)
-	return 1 - math.Pow(x, 2)
+	return T(1 - math.Pow(float64(x), 2))
 }
 
-func Argmax(values []float64) int {
+func Linear[T Float](x T) T {
+	return x
+}
+
+func LinearDerivative[T Float](x T) T {
+	return 1
+}
+
+type activation struct {
+	f, df func(float64) float64
+}
+
+// activations lists well known activation functions by name.
+// Derivatives are expressed in terms of the output of the function.
+var activations = map[string]activation{
+	"sigmoid": {Sigmoid[float64], SigmoidDerivative[float64]},
+	"tanh":    {Tanh[float64], TanhDerivative[float64]},
+	"relu":    {ReLU[float64], ReLUDerivative[float64]},
+	"linear":  {Linear[float64], LinearDerivative[float64]},
+}
+
+// Activation looks up an activation function and its derivative by name,
+// one of sigmoid, tanh, relu and linear.
+func Activation(name string) (f, df func(float64) float64, ok bool) {
+	a, ok := activations[name]
+	return a.f, a.df, ok
+}
+
+func Argmax[T Float](values []T) int {
 	maxValue := math.Inf(-1)
 	maxIndex := -1
 	for i, v := range values {
-		if v > maxValue {
-			maxValue = v
+		if float64(v) > maxValue {
+			maxValue = float64(v)
 			maxIndex = i
 		}
 	}
@@ -65,9 +98,27 @@ func RandomVector(n int) []float64 {
 	return vec
 }
 
+// Convert converts a vector to another precision.
+func Convert[T, U Float](vec []U) []T {
+	result := make([]T, len(vec))
+	for i, v := range vec {
+		result[i] = T(v)
+	}
+	return result
+}
+
+// ConvertMat converts a matrix to another precision.
+func ConvertMat[T, U Float](mat [][]U) [][]T {
+	result := make([][]T, len(mat))
+	for i, row := range mat {
+		result[i] = Convert[T](row)
+	}
+	return result
+}
+
 // FnVec applies given function f to each element of the vector.
-func FnVec(vec []float64, f func(float64) float64) []float64 {
-	result := make([]float64, len(vec))
+func FnVec[T Float](vec []T, f func(T) T) []T {
+	result := make([]T, len(vec))
 	for i, v := range vec {
 		result[i] = f(v)
 	}
@@ -75,8 +126,8 @@ func FnVec(vec []float64, f func(float64) float64) []float64 {
 }
 
 // Scalar multiplies a vector by a scalar.
-func Scalar(vec []float64, scalar float64) []float64 {
-	result := make([]float64, len(vec))
+func Scalar[T Float](vec []T, scalar T) []T {
+	result := make([]T, len(vec))
 	for i, v := range vec {
 		result[i] = v * scalar
 	}
@@ -84,17 +135,17 @@ func Scalar(vec []float64, scalar float64) []float64 {
 }
 
 // Dot computes the dot product of two matrices.
-func Dot(mat1 [][]float64, mat2 [][]float64) [][]float64 {
+func Dot[T Float](mat1 [][]T, mat2 [][]T) [][]T {
 	if len(mat1[0]) != len(mat2) {
 		panic("can't multiply matrices")
 	}
 
-	result := make([][]float64, len(mat1))
+	result := make([][]T, len(mat1))
 
 	for i := 0; i < len(mat1); i++ {
-		result[i] = make([]float64, len(mat2[0]))
+		result[i] = make([]T, len(mat2[0]))
 		for j := 0; j < len(mat2[0]); j++ {
-			dot := 0.0
+			var dot T
 			for k := 0; k < len(mat2); k++ {
 				dot += mat1[i][k] * mat2[k][j]
 			}
@@ -106,10 +157,10 @@ func Dot(mat1 [][]float64, mat2 [][]float64) [][]float64 {
 }
 
 // Transpose computes the transpose of a matrix.
-func Transpose(mat [][]float64) [][]float64 {
-	result := make([][]float64, len(mat[0]))
+func Transpose[T Float](mat [][]T) [][]T {
+	result := make([][]T, len(mat[0]))
 	for i := range result {
-		result[i] = make([]float64, len(mat))
+		result[i] = make([]T, len(mat))
 		for j := range result[i] {
 			result[i][j] = mat[j][i]
 		}
@@ -118,8 +169,8 @@ func Transpose(mat [][]float64) [][]float64 {
 }
 
 // AddVec adds two vectors element-wise.
-func AddVec(vec1, vec2 []float64) []float64 {
-	result := make([]float64, len(vec1))
+func AddVec[T Float](vec1, vec2 []T) []T {
+	result := make([]T, len(vec1))
 	for i := range result {
 		result[i] = vec1[i] + vec2[i]
 	}
@@ -127,8 +178,8 @@ func AddVec(vec1, vec2 []float64) []float64 {
 }
 
 // SubtractVec subtracts one vector from another element-wise.
-func SubtractVec(vec1, vec2 []float64) []float64 {
-	result := make([]float64, len(vec1))
+func SubtractVec[T Float](vec1, vec2 []T) []T {
+	result := make([]T, len(vec1))
 	for i := range result {
 		result[i] = vec1[i] - vec2[i]
 	}
@@ -136,10 +187,10 @@ func SubtractVec(vec1, vec2 []float64) []float64 {
 }
 
 // AddMat adds two matrices element-wise.
-func AddMat(mat1, mat2 [][]float64) [][]float64 {
-	result := make([][]float64, len(mat1))
+func AddMat[T Float](mat1, mat2 [][]T) [][]T {
+	result := make([][]T, len(mat1))
 	for i := range result {
-		result[i] = make([]float64, len(mat1[i]))
+		result[i] = make([]T, len(mat1[i]))
 		for j := range result[i] {
 			result[i][j] = mat1[i][j] + mat2[i][j]
 		}
@@ -148,10 +199,10 @@ func AddMat(mat1, mat2 [][]float64) [][]float64 {
 }
 
 // SubtractMat subtracts one matrix from another element-wise.
-func SubtractMat(mat1, mat2 [][]float64) [][]float64 {
-	result := make([][]float64, len(mat1))
+func SubtractMat[T Float](mat1, mat2 [][]T) [][]T {
+	result := make([][]T, len(mat1))
 	for i := range result {
-		result[i] = make([]float64, len(mat1[i]))
+		result[i] = make([]T, len(mat1[i]))
 		for j := range result[i] {
 			result[i][j] = mat1[i][j] - mat2[i][j]
 		}
@@ -159,34 +210,51 @@ func SubtractMat(mat1, mat2 [][]float64) [][]float64 {
 	return result
 }
 
+// Losses accumulate in float64 whatever the precision of predictions and targets.
+// MeanSquaredError, LogLoss and BinaryLogLoss compute in float64,
+// their Of variants in any precision.
+
 func MeanSquaredError(predictions, targets [][]float64) float64 {
+	return MeanSquaredErrorOf(predictions, targets)
+}
+
+func LogLoss(predictions, targets [][]float64) float64 {
+	return LogLossOf(predictions, targets)
+}
+
+func BinaryLogLoss(predictions, targets [][]float64) float64 {
+	return BinaryLogLossOf(predictions, targets)
+}
+
+func MeanSquaredErrorOf[T Float](predictions, targets [][]T) float64 {
 	totalLoss := 0.0
 	for i, prediction := range predictions {
 		target := targets[i]
 		for j := range prediction {
-			loss := target[j] - prediction[j]
+			loss := float64(target[j] - prediction[j])
 			totalLoss += loss * loss
 		}
 	}
 	return totalLoss / float64(len(predictions))
 }
 
-func LogLoss(predictions, targets [][]float64) float64 {
+func LogLossOf[T Float](predictions, targets [][]T) float64 {
 	totalLoss := 0.0
 	for i, prediction := range predictions {
 		target := targets[i]
 		for j := range prediction {
-			totalLoss += -((target[j] * math.Log(prediction[j])) + ((1 - target[j]) * math.Log(1-prediction[j])))
+			t, p := float64(target[j]), float64(prediction[j])
+			totalLoss += -((t * math.Log(p)) + ((1 - t) * math.Log(1-p)))
 		}
 	}
 	return totalLoss / float64(len(predictions))
 }
 
-func BinaryLogLoss(predictions, targets [][]float64) float64 {
+func BinaryLogLossOf[T Float](predictions, targets [][]T) float64 {
 	totalLoss := 0.0
 	for i, prediction := range predictions {
-		target := targets[i]
-		totalLoss += -((target[0] * math.Log(prediction[0])) + ((1 - target[0]) * math.Log(1-prediction[0])))
+		t, p := float64(targets[i][0]), float64(prediction[0])
+		totalLoss += -((t * math.Log(p)) + ((1 - t) * math.Log(1-p)))
 	}
 	return totalLoss / float64(len(predictions))
 }
@@ -2,14 +2,21 @@ package help
 
 import (
 	"github.com/lnashier/gonet"
+	"io"
 	"os"
+	"path/filepath"
 )
 
+// Save writes the network to the named file.
+// Networks that support it are saved as JSON when the name ends with .json.
 func Save(name string, nn gonet.Network) error {
 	file, err := os.Create(name)
 	if err != nil {
 		panic(err)
 	}
 	defer file.Close()
+	if js, ok := nn.(interface{ SaveJSON(w io.Writer) error }); ok && filepath.Ext(name) == ".json" {
+		return js.SaveJSON(file)
+	}
 	return nn.Save(file)
 }
@@ -4,40 +4,66 @@ import (
 	"context"
 	"fmt"
 	"github.com/lnashier/gonet"
-	"github.com/lnashier/gonet/fns"
+	"github.com/lnashier/gonet/dataset"
+	"github.com/lnashier/gonet/stats"
 	"golang.org/x/sync/errgroup"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
 func Train(ctx context.Context, nn gonet.Network, epochs int, inputs, targets [][]float64, opt ...TrainingOpt) {
+	err := train(ctx, nn, epochs, opt, func(callback func(int) bool) error {
+		nn.Train(epochs, inputs, targets, callback)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DatasetNetwork is a network that can be trained on a dataset.
+type DatasetNetwork interface {
+	gonet.Network
+	TrainDataset(epochs int, ds dataset.Dataset, callback func(int) bool) error
+}
+
+// TrainDataset trains the network like Train, streaming the examples of ds.
+// Reported losses and metrics are those the network records every epoch, see feedforward.Loss.
+func TrainDataset(ctx context.Context, nn DatasetNetwork, epochs int, ds dataset.Dataset, opt ...TrainingOpt) error {
+	return train(ctx, nn, epochs, opt, func(callback func(int) bool) error {
+		return nn.TrainDataset(epochs, ds, callback)
+	})
+}
+
+// train runs the training function, reporting progress and saving checkpoints from its callback.
+func train(ctx context.Context, nn gonet.Network, epochs int, opt []TrainingOpt, run func(callback func(int) bool) error) error {
 	opts := defaultTrainingOpts
 	opts.apply(opt)
 
 	wg, trainCtx := errgroup.WithContext(ctx)
 
 	trainingDone := make(chan struct{})
-	currentEpoch := -1
+	// read by the stats ticker while training runs
+	var lastEpoch atomic.Int64
+	lastEpoch.Store(-1)
 
 	wg.Go(func() error {
-		nn.Train(epochs, inputs, targets, func(epoch int) bool {
-			currentEpoch = epoch
-
-			if currentEpoch%(epochs/10) == 0 {
-				stats := nn.EpochStats(currentEpoch)
-				if stats.Inputs != 0 {
-					end := stats.End
-					if end.IsZero() {
-						end = time.Now()
-					}
-					fmt.Printf("Epoch:(%d) Inputs:(%d) Duration:(%v)\n", stats.ID, stats.Inputs, end.Sub(stats.Start))
-				}
+		defer close(trainingDone)
+		return run(func(epoch int) bool {
+			lastEpoch.Store(int64(epoch))
 
-				predictions := make([][]float64, len(inputs))
-				for i, input := range inputs {
-					predictions[i] = nn.Predict(input)
-				}
+			if epoch%max(epochs/10, 1) == 0 {
+				report(nn.EpochStats(epoch))
+			}
 
-				fmt.Printf("Epoch %04d, Loss: %f\n", currentEpoch, opts.lossFunc(predictions, targets))
+			if opts.checkpointEvery > 0 && (epoch+1)%opts.checkpointEvery == 0 {
+				name := strings.ReplaceAll(opts.checkpoint, "{epoch}", strconv.Itoa(epoch))
+				if err := Save(name, nn); err != nil {
+					fmt.Printf("Epoch %04d, Checkpoint %s: %v\n", epoch, name, err)
+				}
 			}
 
 			contTraining := true
@@ -48,47 +74,87 @@ func Train(ctx context.Context, nn gonet.Network, epochs int, inputs, targets []
 			}
 			return contTraining
 		})
-		trainingDone <- struct{}{}
-		return nil
 	})
 
 	wg.Go(func() error {
 		ticker := time.NewTicker(opts.echoStatsEvery)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-trainingDone:
 				return nil
 			case <-ticker.C:
-				stats := nn.EpochStats(currentEpoch + 1)
-				if stats.Inputs != 0 {
-					end := stats.End
-					if end.IsZero() {
-						end = time.Now()
-					}
-					fmt.Printf("Epoch:(%d) Inputs:(%d) Duration:(%v)\n", stats.ID, stats.Inputs, end.Sub(stats.Start))
-				}
+				echo(nn.EpochStats(int(lastEpoch.Load()) + 1))
 			}
 		}
 	})
 
-	err := wg.Wait()
-	if err != nil {
-		panic(err)
+	if err := wg.Wait(); err != nil {
+		return err
 	}
 
 	fmt.Println("Training Duration", nn.TrainingDuration())
+
+	if opts.history != "" {
+		if h, ok := nn.(interface{ History() *stats.Training }); ok {
+			if history := h.History(); history != nil {
+				return history.Save(opts.history)
+			}
+		}
+	}
+	return nil
+}
+
+// report prints the losses and metrics of a finished epoch, as recorded by the network, then its statistics.
+func report(e stats.Epoch) {
+	if e.End.IsZero() {
+		return
+	}
+	fmt.Printf("Epoch %04d, Loss: %f%s\n", e.ID, e.TrainLoss, scores(e.Metrics))
+	if e.ValidationInputs != 0 {
+		fmt.Printf("Epoch %04d, Validation Loss: %f%s\n", e.ID, e.ValidationLoss, scores(e.ValidationMetrics))
+	}
+	fmt.Printf("Epoch:(%d) Inputs:(%d) Duration:(%v) LearningRate:(%.6g) GradNorm:(%.6g) WeightNorm:(%.6g)\n",
+		e.ID, e.Inputs, e.End.Sub(e.Start), e.LearningRate, e.GradNorm, e.WeightNorm)
+}
+
+// echo prints the progress of an epoch.
+func echo(e stats.Epoch) {
+	if e.Inputs == 0 {
+		return
+	}
+	end := e.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	fmt.Printf("Epoch:(%d) Inputs:(%d) Duration:(%v)\n", e.ID, e.Inputs, end.Sub(e.Start))
+}
+
+// scores formats metrics by name for printing after a loss.
+func scores(metrics map[string]float64) string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, ", %s: %f", name, metrics[name])
+	}
+	return b.String()
 }
 
 type TrainingOpt func(*trainingOpts)
 
 type trainingOpts struct {
-	echoStatsEvery time.Duration
-	lossFunc       func(predictions, targets [][]float64) float64
+	echoStatsEvery  time.Duration
+	checkpoint      string
+	checkpointEvery int
+	history         string
 }
 
 var defaultTrainingOpts = trainingOpts{
 	echoStatsEvery: 5 * time.Second,
-	lossFunc:       fns.MeanSquaredError, // Default loss function MSE
 }
 
 func (s *trainingOpts) apply(opts []TrainingOpt) {
@@ -103,9 +169,19 @@ func EchoStatsEvery(v time.Duration) TrainingOpt {
 	}
 }
 
-// LossFunc sets the loss function for the network being trained.
-func LossFunc(v func(predictions, targets [][]float64) float64) TrainingOpt {
+// Checkpoint saves the network every given number of epochs.
+// Occurrences of {epoch} in name are replaced with the epoch number.
+func Checkpoint(name string, every int) TrainingOpt {
+	return func(s *trainingOpts) {
+		s.checkpoint = name
+		s.checkpointEvery = every
+	}
+}
+
+// History saves the statistics of every epoch to the named file once trained,
+// as CSV when the name ends with .csv and as JSON otherwise, see package plot to chart them.
+func History(name string) TrainingOpt {
 	return func(s *trainingOpts) {
-		s.lossFunc = v
+		s.history = name
 	}
 }
@@ -0,0 +1,79 @@
+package idx
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/dataset"
+)
+
+// Dataset pairs the items of inputs, flattened, with the items of targets.
+// One-dimensional targets are class labels, one-hot encoded.
+// Values are converted to float64 as examples are read, the arrays staying in their compact types.
+func Dataset(inputs, targets *Array, opt ...Opt) (dataset.Indexed, error) {
+	opts := defaultDatasetOpts
+	opts.apply(opt)
+
+	if inputs.Len() != targets.Len() {
+		return nil, fmt.Errorf("idx: %d inputs but %d targets", inputs.Len(), targets.Len())
+	}
+	ds := &idxDataset{
+		inputs:  inputs,
+		targets: targets,
+		scale:   opts.scale,
+		classes: opts.classes,
+	}
+	if len(targets.Dims) == 1 {
+		switch targets.Type {
+		case Float32, Float64:
+			return nil, fmt.Errorf("idx: labels of type %s", targets.Type)
+		}
+		if ds.classes == 0 {
+			for i := range targets.Len() {
+				ds.classes = max(ds.classes, int(targets.Float64(i))+1)
+			}
+		}
+		for i := range targets.Len() {
+			if l := int(targets.Float64(i)); l < 0 || l >= ds.classes {
+				return nil, fmt.Errorf("idx: label %d of item %d out of %d classes", l, i, ds.classes)
+			}
+		}
+	}
+	return ds, nil
+}
+
+// Load reads the named inputs and targets files as a dataset, see Dataset.
+func Load(inputsName, targetsName string, opt ...Opt) (dataset.Indexed, error) {
+	inputs, err := ReadFile(inputsName)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := ReadFile(targetsName)
+	if err != nil {
+		return nil, err
+	}
+	return Dataset(inputs, targets, opt...)
+}
+
+type idxDataset struct {
+	inputs, targets *Array
+	scale           float64
+	classes         int // one-hot encoded labels, 0 if targets are not labels
+}
+
+func (d *idxDataset) Len() int {
+	return d.inputs.Len()
+}
+
+func (d *idxDataset) At(i int) dataset.Example {
+	ex := dataset.Example{Input: d.inputs.Float64s(i, i+1, d.scale)}
+	if len(d.targets.Dims) == 1 {
+		ex.Target = make([]float64, d.classes)
+		ex.Target[int(d.targets.Float64(i))] = 1
+	} else {
+		ex.Target = d.targets.Float64s(i, i+1, 1)
+	}
+	return ex
+}
+
+func (d *idxDataset) Iter() dataset.Iterator {
+	return dataset.IterIndexed(d, nil)
+}
@@ -0,0 +1,348 @@
+// Package idx reads and writes IDX files, the format of the MNIST database,
+// and loads pairs of them as datasets.
+//
+// An IDX file starts with two zero bytes, the type of its values, the number of dimensions
+// and every dimension as a big-endian uint32, followed by the values in row-major order.
+package idx
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Type is the type of the values of an IDX file.
+type Type byte
+
+const (
+	Uint8   Type = 0x08
+	Int8    Type = 0x09
+	Int16   Type = 0x0B
+	Int32   Type = 0x0C
+	Float32 Type = 0x0D
+	Float64 Type = 0x0E
+)
+
+// Size returns the number of bytes of a value, 0 for unknown types.
+func (t Type) Size() int {
+	switch t {
+	case Uint8, Int8:
+		return 1
+	case Int16:
+		return 2
+	case Int32, Float32:
+		return 4
+	case Float64:
+		return 8
+	}
+	return 0
+}
+
+func (t Type) String() string {
+	switch t {
+	case Uint8:
+		return "uint8"
+	case Int8:
+		return "int8"
+	case Int16:
+		return "int16"
+	case Int32:
+		return "int32"
+	case Float32:
+		return "float32"
+	case Float64:
+		return "float64"
+	}
+	return fmt.Sprintf("Type(%#02x)", byte(t))
+}
+
+// Array is the content of an IDX file.
+type Array struct {
+	Type Type
+	Dims []int
+	// Data holds the values in row-major order, as a slice of the Go type matching Type:
+	// []uint8, []int8, []int16, []int32, []float32 or []float64.
+	Data any
+}
+
+// maxSize bounds the number of values of an array, to fail on corrupt headers before allocating.
+const maxSize = 1 << 34
+
+// Len returns the number of items of the array, its first dimension.
+func (a *Array) Len() int {
+	if len(a.Dims) == 0 {
+		return 0
+	}
+	return a.Dims[0]
+}
+
+// ItemSize returns the number of values of an item, the product of all dimensions but the first.
+func (a *Array) ItemSize() int {
+	size := 1
+	for _, d := range a.Dims[min(len(a.Dims), 1):] {
+		size *= d
+	}
+	return size
+}
+
+// Float64 returns value i of the array.
+func (a *Array) Float64(i int) float64 {
+	switch data := a.Data.(type) {
+	case []uint8:
+		return float64(data[i])
+	case []int8:
+		return float64(data[i])
+	case []int16:
+		return float64(data[i])
+	case []int32:
+		return float64(data[i])
+	case []float32:
+		return float64(data[i])
+	case []float64:
+		return data[i]
+	}
+	panic(fmt.Sprintf("idx: unsupported data %T", a.Data))
+}
+
+// Float64s returns the values of items [i, j) as float64, each multiplied by scale.
+func (a *Array) Float64s(i, j int, scale float64) []float64 {
+	size := a.ItemSize()
+	lo, hi := i*size, j*size
+	values := make([]float64, hi-lo)
+	switch data := a.Data.(type) {
+	case []uint8:
+		scaled(values, data[lo:hi], scale)
+	case []int8:
+		scaled(values, data[lo:hi], scale)
+	case []int16:
+		scaled(values, data[lo:hi], scale)
+	case []int32:
+		scaled(values, data[lo:hi], scale)
+	case []float32:
+		scaled(values, data[lo:hi], scale)
+	case []float64:
+		scaled(values, data[lo:hi], scale)
+	default:
+		panic(fmt.Sprintf("idx: unsupported data %T", a.Data))
+	}
+	return values
+}
+
+func scaled[V uint8 | int8 | int16 | int32 | float32 | float64](dst []float64, src []V, scale float64) {
+	for i, v := range src {
+		dst[i] = float64(v) * scale
+	}
+}
+
+// New returns an array of the given type and dimensions holding data,
+// checking that the slice type and length match.
+func New(dims []int, data any) (*Array, error) {
+	a := &Array{Dims: dims, Data: data}
+	var n int
+	switch data := data.(type) {
+	case []uint8:
+		a.Type, n = Uint8, len(data)
+	case []int8:
+		a.Type, n = Int8, len(data)
+	case []int16:
+		a.Type, n = Int16, len(data)
+	case []int32:
+		a.Type, n = Int32, len(data)
+	case []float32:
+		a.Type, n = Float32, len(data)
+	case []float64:
+		a.Type, n = Float64, len(data)
+	default:
+		return nil, fmt.Errorf("idx: unsupported data %T", data)
+	}
+	if len(dims) == 0 || len(dims) > math.MaxUint8 {
+		return nil, fmt.Errorf("idx: %d dimensions", len(dims))
+	}
+	size := 1
+	for _, d := range dims {
+		if d < 0 || d > math.MaxUint32 {
+			return nil, fmt.Errorf("idx: invalid dimensions %v", dims)
+		}
+		size *= d
+	}
+	if size != n {
+		return nil, fmt.Errorf("idx: %d values don't fit dimensions %v", n, dims)
+	}
+	return a, nil
+}
+
+// Read reads an IDX file, gzip compressed or not.
+func Read(r io.Reader) (*Array, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return read(gz)
+	}
+	return read(br)
+}
+
+func read(r io.Reader) (*Array, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("idx: reading header: %w", err)
+	}
+	if header[0] != 0 || header[1] != 0 {
+		return nil, fmt.Errorf("idx: bad magic %#x, not an IDX file", header)
+	}
+	t := Type(header[2])
+	if t.Size() == 0 {
+		return nil, fmt.Errorf("idx: unknown type %#02x", header[2])
+	}
+	if header[3] == 0 {
+		return nil, errors.New("idx: no dimensions")
+	}
+
+	dimBytes := make([]byte, 4*int(header[3]))
+	if _, err := io.ReadFull(r, dimBytes); err != nil {
+		return nil, fmt.Errorf("idx: reading dimensions: %w", err)
+	}
+	dims := make([]int, header[3])
+	size := 1
+	for i := range dims {
+		dims[i] = int(binary.BigEndian.Uint32(dimBytes[4*i:]))
+		if dims[i] > 0 && size > maxSize/dims[i] {
+			return nil, fmt.Errorf("idx: dimensions %v too large", dims[:i+1])
+		}
+		size *= dims[i]
+	}
+
+	// read what is there rather than trusting the header with a large allocation
+	n := int64(size * t.Size())
+	raw, err := io.ReadAll(io.LimitReader(r, n))
+	if err != nil {
+		return nil, fmt.Errorf("idx: reading %s values %v: %w", t, dims, err)
+	}
+	if int64(len(raw)) != n {
+		return nil, fmt.Errorf("idx: %d of %d bytes of %s values %v: %w", len(raw), n, t, dims, io.ErrUnexpectedEOF)
+	}
+	return &Array{Type: t, Dims: dims, Data: decode(t, raw)}, nil
+}
+
+// decode converts big-endian values to a slice of the Go type matching t.
+func decode(t Type, raw []byte) any {
+	switch t {
+	case Uint8:
+		return raw
+	case Int8:
+		data := make([]int8, len(raw))
+		for i, b := range raw {
+			data[i] = int8(b)
+		}
+		return data
+	case Int16:
+		data := make([]int16, len(raw)/2)
+		for i := range data {
+			data[i] = int16(binary.BigEndian.Uint16(raw[2*i:]))
+		}
+		return data
+	case Int32:
+		data := make([]int32, len(raw)/4)
+		for i := range data {
+			data[i] = int32(binary.BigEndian.Uint32(raw[4*i:]))
+		}
+		return data
+	case Float32:
+		data := make([]float32, len(raw)/4)
+		for i := range data {
+			data[i] = math.Float32frombits(binary.BigEndian.Uint32(raw[4*i:]))
+		}
+		return data
+	default:
+		data := make([]float64, len(raw)/8)
+		for i := range data {
+			data[i] = math.Float64frombits(binary.BigEndian.Uint64(raw[8*i:]))
+		}
+		return data
+	}
+}
+
+// Write writes the array as an uncompressed IDX file, of the type matching its data.
+func Write(w io.Writer, a *Array) error {
+	a, err := New(a.Dims, a.Data)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write([]byte{0, 0, byte(a.Type), byte(len(a.Dims))})
+	for _, d := range a.Dims {
+		bw.Write(binary.BigEndian.AppendUint32(nil, uint32(d)))
+	}
+	var b [8]byte
+	switch data := a.Data.(type) {
+	case []uint8:
+		bw.Write(data)
+	case []int8:
+		for _, v := range data {
+			bw.WriteByte(byte(v))
+		}
+	case []int16:
+		for _, v := range data {
+			bw.Write(binary.BigEndian.AppendUint16(b[:0], uint16(v)))
+		}
+	case []int32:
+		for _, v := range data {
+			bw.Write(binary.BigEndian.AppendUint32(b[:0], uint32(v)))
+		}
+	case []float32:
+		for _, v := range data {
+			bw.Write(binary.BigEndian.AppendUint32(b[:0], math.Float32bits(v)))
+		}
+	case []float64:
+		for _, v := range data {
+			bw.Write(binary.BigEndian.AppendUint64(b[:0], math.Float64bits(v)))
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadFile reads the named IDX file, gzip compressed or not.
+func ReadFile(name string) (*Array, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	a, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return a, nil
+}
+
+// WriteFile writes the array to the named file, gzip compressed if the name ends with .gz.
+func WriteFile(name string, a *Array) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if filepath.Ext(name) == ".gz" {
+		gz := gzip.NewWriter(f)
+		if err := Write(gz, a); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	} else if err := Write(f, a); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,33 @@
+package idx
+
+type Opt func(*datasetOpts)
+
+type datasetOpts struct {
+	scale   float64
+	classes int
+}
+
+var defaultDatasetOpts = datasetOpts{
+	scale: 1,
+}
+
+func (s *datasetOpts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Scale multiplies input values, e.g. by 1/255 to bring pixels to [0, 1]. Defaults to 1.
+func Scale(v float64) Opt {
+	return func(s *datasetOpts) {
+		s.scale = v
+	}
+}
+
+// Classes sets the number of classes labels are one-hot encoded into.
+// Defaults to as many as the largest label needs.
+func Classes(v int) Opt {
+	return func(s *datasetOpts) {
+		s.classes = v
+	}
+}
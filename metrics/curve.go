@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+)
+
+// Curve is a curve of a binary classifier over its decision thresholds, in decreasing order.
+type Curve struct {
+	X          []float64
+	Y          []float64
+	Thresholds []float64
+}
+
+// ROC returns the receiver operating characteristic of scores for the positive labels,
+// the false positive rate as X and the true positive rate as Y.
+func ROC(scores []float64, labels []bool) Curve {
+	curve := Curve{X: []float64{0}, Y: []float64{0}, Thresholds: []float64{math.Inf(1)}}
+	positives, negatives := count(labels)
+	walk(scores, labels, func(threshold float64, tp, fp int) {
+		curve.X = append(curve.X, ratio(fp, negatives))
+		curve.Y = append(curve.Y, ratio(tp, positives))
+		curve.Thresholds = append(curve.Thresholds, threshold)
+	})
+	return curve
+}
+
+// PR returns the precision-recall curve of scores for the positive labels,
+// the recall as X and the precision as Y.
+func PR(scores []float64, labels []bool) Curve {
+	var curve Curve
+	positives, _ := count(labels)
+	walk(scores, labels, func(threshold float64, tp, fp int) {
+		curve.X = append(curve.X, ratio(tp, positives))
+		curve.Y = append(curve.Y, ratio(tp, tp+fp))
+		curve.Thresholds = append(curve.Thresholds, threshold)
+	})
+	return curve
+}
+
+// Area returns the area under the curve, interpolating linearly.
+func (c Curve) Area() float64 {
+	var area float64
+	for i := 1; i < len(c.X); i++ {
+		area += (c.X[i] - c.X[i-1]) * (c.Y[i] + c.Y[i-1]) / 2
+	}
+	return area
+}
+
+// AveragePrecision returns the precisions of a precision-recall curve weighted by their increase in recall.
+// Unlike Area it does not interpolate, which would be optimistic.
+func (c Curve) AveragePrecision() float64 {
+	var area, recall float64
+	for i := range c.X {
+		area += (c.X[i] - recall) * c.Y[i]
+		recall = c.X[i]
+	}
+	return area
+}
+
+// AUC returns the area under the ROC curve, averaged over the classes one versus the rest for more than 2 classes.
+func AUC(predictions, targets [][]float64) float64 {
+	return oneVsRest(predictions, targets, func(scores []float64, labels []bool) float64 {
+		return ROC(scores, labels).Area()
+	})
+}
+
+// PRAUC returns the average precision of the precision-recall curve,
+// averaged over the classes one versus the rest for more than 2 classes.
+func PRAUC(predictions, targets [][]float64) float64 {
+	return oneVsRest(predictions, targets, func(scores []float64, labels []bool) float64 {
+		return PR(scores, labels).AveragePrecision()
+	})
+}
+
+func oneVsRest(predictions, targets [][]float64, f func(scores []float64, labels []bool) float64) float64 {
+	if len(predictions) == 0 {
+		return 0
+	}
+	scores := make([]float64, len(predictions))
+	labels := make([]bool, len(predictions))
+	if len(predictions[0]) == 1 {
+		for i, p := range predictions {
+			scores[i], labels[i] = p[0], targets[i][0] >= 0.5
+		}
+		return f(scores, labels)
+	}
+
+	var total float64
+	var classes int
+	for c := range predictions[0] {
+		for i, p := range predictions {
+			scores[i], labels[i] = p[c], class(targets[i]) == c
+		}
+		// classes without targets have no curve
+		if positives, _ := count(labels); positives > 0 {
+			total += f(scores, labels)
+			classes++
+		}
+	}
+	if classes == 0 {
+		return 0
+	}
+	return total / float64(classes)
+}
+
+// walk calls point with the true and false positives of every distinct threshold of scores, highest first.
+func walk(scores []float64, labels []bool, point func(threshold float64, tp, fp int)) {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if scores[a] > scores[b] {
+			return -1
+		}
+		if scores[a] < scores[b] {
+			return 1
+		}
+		return 0
+	})
+	var tp, fp int
+	for i, e := range order {
+		if labels[e] {
+			tp++
+		} else {
+			fp++
+		}
+		if i == len(order)-1 || scores[order[i+1]] != scores[e] {
+			point(scores[e], tp, fp)
+		}
+	}
+}
+
+func count(labels []bool) (positives, negatives int) {
+	for _, l := range labels {
+		if l {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	return positives, negatives
+}
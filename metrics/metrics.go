@@ -0,0 +1,222 @@
+// Package metrics scores predictions of a network against targets.
+//
+// Metrics share the signature of the loss functions of package fns. Classification metrics
+// take the class of a row to be the index of its largest value or, for rows of a single value,
+// 1 if it is at least 0.5 and 0 otherwise.
+package metrics
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"slices"
+	"strings"
+)
+
+// Metric scores predictions against targets.
+type Metric func(predictions, targets [][]float64) float64
+
+var metrics = map[string]Metric{
+	"accuracy":           Accuracy,
+	"top5":               TopK(5),
+	"precision":          Precision(Macro),
+	"precision_micro":    Precision(Micro),
+	"precision_weighted": Precision(Weighted),
+	"recall":             Recall(Macro),
+	"recall_micro":       Recall(Micro),
+	"recall_weighted":    Recall(Weighted),
+	"f1":                 F1(Macro),
+	"f1_micro":           F1(Micro),
+	"f1_weighted":        F1(Weighted),
+	"precision_binary":   Precision(Binary),
+	"recall_binary":      Recall(Binary),
+	"f1_binary":          F1(Binary),
+	"auc":                AUC,
+	"prauc":              PRAUC,
+	"logloss":            LogLoss,
+	"mse":                fns.MeanSquaredError,
+	"mae":                MAE,
+	"rmse":               RMSE,
+	"r2":                 R2,
+	"mape":               MAPE,
+}
+
+// Lookup returns the metric of the given name, e.g. "accuracy", "f1", "f1_weighted" or "rmse".
+func Lookup(name string) (Metric, bool) {
+	m, ok := metrics[strings.ToLower(name)]
+	return m, ok
+}
+
+// Names returns the names of the metrics known to Lookup.
+func Names() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Average is how per class scores are combined.
+type Average int
+
+const (
+	// Micro scores the counts of all classes together.
+	Micro Average = iota
+	// Macro averages the scores of the classes.
+	Macro
+	// Weighted averages the scores of the classes weighted by their number of targets.
+	Weighted
+	// Binary scores the positive class 1 only, e.g. of single value targets.
+	Binary
+)
+
+// Accuracy returns the fraction of predictions of the target class.
+func Accuracy(predictions, targets [][]float64) float64 {
+	var correct int
+	for i, p := range predictions {
+		if class(p) == class(targets[i]) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(predictions))
+}
+
+// TopK returns a metric of the fraction of targets whose class is among the k highest predictions.
+func TopK(k int) Metric {
+	return func(predictions, targets [][]float64) float64 {
+		var correct int
+		for i, p := range predictions {
+			c := class(targets[i])
+			if len(p) == 1 {
+				p = []float64{1 - p[0], p[0]}
+			}
+			higher := 0
+			for _, v := range p {
+				if v > p[c] {
+					higher++
+				}
+			}
+			if higher < k {
+				correct++
+			}
+		}
+		return float64(correct) / float64(len(predictions))
+	}
+}
+
+// Precision returns a metric of the fraction of predictions of a class that are of the class.
+func Precision(avg Average) Metric {
+	return func(predictions, targets [][]float64) float64 {
+		return ConfusionMatrix(predictions, targets).score(avg, func(tp, fp, fn int) float64 {
+			return ratio(tp, tp+fp)
+		})
+	}
+}
+
+// Recall returns a metric of the fraction of targets of a class that are predicted.
+func Recall(avg Average) Metric {
+	return func(predictions, targets [][]float64) float64 {
+		return ConfusionMatrix(predictions, targets).score(avg, func(tp, fp, fn int) float64 {
+			return ratio(tp, tp+fn)
+		})
+	}
+}
+
+// F1 returns a metric of the harmonic mean of precision and recall.
+func F1(avg Average) Metric {
+	return func(predictions, targets [][]float64) float64 {
+		return ConfusionMatrix(predictions, targets).score(avg, func(tp, fp, fn int) float64 {
+			return ratio(2*tp, 2*tp+fp+fn)
+		})
+	}
+}
+
+// Confusion counts predictions by class, Confusion[target][prediction].
+type Confusion [][]int
+
+// ConfusionMatrix returns the confusion matrix of predictions.
+func ConfusionMatrix(predictions, targets [][]float64) Confusion {
+	n := 2
+	if len(targets) > 0 && len(targets[0]) > 1 {
+		n = len(targets[0])
+	}
+	c := make(Confusion, n)
+	for i := range c {
+		c[i] = make([]int, n)
+	}
+	for i, p := range predictions {
+		c[class(targets[i])][class(p)]++
+	}
+	return c
+}
+
+func (c Confusion) String() string {
+	var b strings.Builder
+	for _, row := range c {
+		for j, v := range row {
+			if j > 0 {
+				b.WriteByte('\t')
+			}
+			fmt.Fprint(&b, v)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// score combines a score of the true positives, false positives and false negatives of each class.
+func (c Confusion) score(avg Average, f func(tp, fp, fn int) float64) float64 {
+	classes := make([][3]int, len(c))
+	for i := range c {
+		for j, v := range c[i] {
+			if i == j {
+				classes[i][0] += v
+			} else {
+				classes[j][1] += v
+				classes[i][2] += v
+			}
+		}
+	}
+	switch avg {
+	case Binary:
+		return f(classes[1][0], classes[1][1], classes[1][2])
+	case Micro:
+		var tp, fp, fn int
+		for _, k := range classes {
+			tp, fp, fn = tp+k[0], fp+k[1], fn+k[2]
+		}
+		return f(tp, fp, fn)
+	case Weighted:
+		var score float64
+		var total int
+		for _, k := range classes {
+			support := k[0] + k[2]
+			score += float64(support) * f(k[0], k[1], k[2])
+			total += support
+		}
+		return score / float64(total)
+	default:
+		var score float64
+		for _, k := range classes {
+			score += f(k[0], k[1], k[2])
+		}
+		return score / float64(len(classes))
+	}
+}
+
+func class(values []float64) int {
+	if len(values) == 1 {
+		if values[0] >= 0.5 {
+			return 1
+		}
+		return 0
+	}
+	return fns.Argmax(values)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func near(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	}
+}
+
+// binary targets of single values, predicted classes 1 1 0 1 0 0 0 1
+var (
+	binaryPredictions = [][]float64{{0.9}, {0.8}, {0.3}, {0.7}, {0.2}, {0.1}, {0.4}, {0.6}}
+	binaryTargets     = [][]float64{{1}, {1}, {1}, {0}, {0}, {0}, {0}, {0}}
+)
+
+func TestBinary(t *testing.T) {
+	// class 1: 2 true positives, 2 false positives, 1 false negative
+	// class 0: 3 true positives, 1 false positive, 2 false negatives
+	tests := map[string]struct {
+		metric Metric
+		want   float64
+	}{
+		"precision binary":  {Precision(Binary), 2.0 / 4},
+		"recall binary":     {Recall(Binary), 2.0 / 3},
+		"f1 binary":         {F1(Binary), 4.0 / 7},
+		"precision macro":   {Precision(Macro), (2.0/4 + 3.0/4) / 2},
+		"recall macro":      {Recall(Macro), (2.0/3 + 3.0/5) / 2},
+		"f1 macro":          {F1(Macro), (4.0/7 + 6.0/9) / 2},
+		"f1 micro":          {F1(Micro), 5.0 / 8},
+		"f1 weighted":       {F1(Weighted), (3*4.0/7 + 5*6.0/9) / 8},
+		"accuracy":          {Accuracy, 5.0 / 8},
+		"auc":               {AUC, 12.0 / 15},
+		"average precision": {PRAUC, (1 + 1 + 3.0/6) / 3},
+		"top1":              {TopK(1), 5.0 / 8},
+		"top2":              {TopK(2), 1},
+	}
+	for name, test := range tests {
+		near(t, name, test.metric(binaryPredictions, binaryTargets), test.want)
+	}
+}
+
+func TestMulticlass(t *testing.T) {
+	// targets 0 0 1 1 1 2 2, predicted 0 1 1 1 1 2 0
+	classes := func(cs ...int) [][]float64 {
+		rows := make([][]float64, len(cs))
+		for i, c := range cs {
+			rows[i] = make([]float64, 3)
+			rows[i][c] = 1
+		}
+		return rows
+	}
+	predictions := classes(0, 1, 1, 1, 1, 2, 0)
+	targets := classes(0, 0, 1, 1, 1, 2, 2)
+
+	want := Confusion{{1, 1, 0}, {0, 3, 0}, {1, 0, 1}}
+	if got := ConfusionMatrix(predictions, targets); got.String() != want.String() {
+		t.Fatalf("got confusion\n%v, want\n%v", got, want)
+	}
+
+	// per class precision 1/2 3/4 1, recall 1/2 1 1/2, F1 1/2 6/7 2/3, supports 2 3 2
+	tests := map[string]struct {
+		metric Metric
+		want   float64
+	}{
+		"precision macro":    {Precision(Macro), (0.5 + 0.75 + 1) / 3},
+		"recall macro":       {Recall(Macro), (0.5 + 1 + 0.5) / 3},
+		"f1 macro":           {F1(Macro), (0.5 + 6.0/7 + 2.0/3) / 3},
+		"precision micro":    {Precision(Micro), 5.0 / 7},
+		"recall micro":       {Recall(Micro), 5.0 / 7},
+		"f1 micro":           {F1(Micro), 5.0 / 7},
+		"precision weighted": {Precision(Weighted), (2*0.5 + 3*0.75 + 2*1) / 7},
+		"recall weighted":    {Recall(Weighted), (2*0.5 + 3*1 + 2*0.5) / 7},
+		"f1 weighted":        {F1(Weighted), (2*0.5 + 3*6.0/7 + 2*2.0/3) / 7},
+		"precision binary":   {Precision(Binary), 0.75},
+		"accuracy":           {Accuracy, 5.0 / 7},
+	}
+	for name, test := range tests {
+		near(t, name, test.metric(predictions, targets), test.want)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	scores := []float64{0.9, 0.8, 0.3, 0.7, 0.2, 0.1, 0.4, 0.6, 0.8}
+	labels := []bool{true, true, true, false, false, false, false, false, false}
+
+	// the tied 0.8 scores are a single point
+	roc := ROC(scores, labels)
+	wantX := []float64{0, 0, 1.0 / 6, 2.0 / 6, 3.0 / 6, 4.0 / 6, 4.0 / 6, 5.0 / 6, 1}
+	wantY := []float64{0, 1.0 / 3, 2.0 / 3, 2.0 / 3, 2.0 / 3, 2.0 / 3, 1, 1, 1}
+	if len(roc.X) != len(wantX) {
+		t.Fatalf("got ROC %v %v, want %v %v", roc.X, roc.Y, wantX, wantY)
+	}
+	for i := range wantX {
+		near(t, "ROC x", roc.X[i], wantX[i])
+		near(t, "ROC y", roc.Y[i], wantY[i])
+	}
+	// 0.9 beats 6 negatives, 0.8 beats 5 and ties 1, 0.3 beats 2
+	near(t, "ROC area", roc.Area(), (6+5.5+2)/18)
+
+	// precision at recalls 1/3 2/3 1: 1, 2/3, 3/7
+	pr := PR(scores, labels)
+	near(t, "average precision", pr.AveragePrecision(), (1+2.0/3+3.0/7)/3)
+}
+
+func TestAUCMulticlass(t *testing.T) {
+	predictions := [][]float64{{0.8, 0.1, 0.1}, {0.2, 0.6, 0.2}, {0.1, 0.2, 0.7}, {0.3, 0.5, 0.2}}
+	targets := [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 0, 1}}
+	// classes 0 and 1 are ranked perfectly, class 2 scores 0.2 for one of its targets,
+	// tied with a target of class 1
+	near(t, "auc", AUC(predictions, targets), (1+1+3.5/4)/3)
+}
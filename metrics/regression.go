@@ -0,0 +1,99 @@
+package metrics
+
+import "math"
+
+// epsilon keeps log loss finite for predictions of exactly 0 or 1.
+const epsilon = 1e-15
+
+// LogLoss returns the cross-entropy of predicted probabilities, binary for rows of a single value
+// and categorical otherwise.
+func LogLoss(predictions, targets [][]float64) float64 {
+	var loss float64
+	for i, p := range predictions {
+		t := targets[i]
+		if len(p) == 1 {
+			q := min(max(p[0], epsilon), 1-epsilon)
+			loss -= t[0]*math.Log(q) + (1-t[0])*math.Log(1-q)
+			continue
+		}
+		for j, v := range p {
+			if t[j] != 0 {
+				loss -= t[j] * math.Log(min(max(v, epsilon), 1))
+			}
+		}
+	}
+	return loss / float64(len(predictions))
+}
+
+// MAE returns the mean absolute error of all values.
+func MAE(predictions, targets [][]float64) float64 {
+	var sum float64
+	var n int
+	for i, p := range predictions {
+		for j, v := range p {
+			sum += math.Abs(v - targets[i][j])
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+// RMSE returns the root mean squared error of all values.
+func RMSE(predictions, targets [][]float64) float64 {
+	var sum float64
+	var n int
+	for i, p := range predictions {
+		for j, v := range p {
+			sum += (v - targets[i][j]) * (v - targets[i][j])
+			n++
+		}
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// MAPE returns the mean absolute error of all values relative to their targets, as a fraction.
+// Targets of 0 are left out.
+func MAPE(predictions, targets [][]float64) float64 {
+	var sum float64
+	var n int
+	for i, p := range predictions {
+		for j, v := range p {
+			if t := targets[i][j]; t != 0 {
+				sum += math.Abs((v - t) / t)
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// R2 returns the coefficient of determination, the fraction of the variance of targets explained
+// by predictions, averaged over outputs. Constant outputs score 1 if predicted exactly and 0 otherwise.
+func R2(predictions, targets [][]float64) float64 {
+	if len(targets) == 0 {
+		return 0
+	}
+	outputs := len(targets[0])
+	var total float64
+	for j := range outputs {
+		var mean float64
+		for _, t := range targets {
+			mean += t[j] / float64(len(targets))
+		}
+		var residual, variance float64
+		for i, p := range predictions {
+			residual += (targets[i][j] - p[j]) * (targets[i][j] - p[j])
+			variance += (targets[i][j] - mean) * (targets[i][j] - mean)
+		}
+		switch {
+		case variance != 0:
+			total += 1 - residual/variance
+		case residual == 0:
+			total++
+		}
+	}
+	return total / float64(outputs)
+}
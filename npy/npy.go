@@ -0,0 +1,224 @@
+// Package npy reads and writes NumPy .npy arrays and .npz archives of network weights.
+package npy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var magic = []byte("\x93NUMPY")
+
+const (
+	// maxSize bounds the number of values of an array, to fail on corrupt headers before allocating.
+	maxSize = 1 << 34
+	// chunk is the number of values read at a time.
+	chunk = 1 << 16
+)
+
+// Array is an n-dimensional array in row-major (C) order.
+type Array struct {
+	Shape []int
+	Data  []float64
+}
+
+// Write writes data as a version 1.0 .npy array of the given shape,
+// in little-endian float32 ('<f4') or float64 ('<f8') matching T.
+func Write[T fns.Float](w io.Writer, shape []int, data []T) error {
+	size := 1
+	for _, d := range shape {
+		size *= d
+	}
+	if size != len(data) {
+		return fmt.Errorf("npy: %d values don't fit shape %v", len(data), shape)
+	}
+
+	var zero T
+	descr := fmt.Sprintf("<f%d", unsafe.Sizeof(zero))
+
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	tuple := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, tuple)
+	// magic, version and header length take 10 bytes, the header ends with a newline
+	// and is padded so that data starts on a 64-byte boundary
+	header += strings.Repeat(" ", 63-(10+len(header))%64) + "\n"
+	if len(header) > math.MaxUint16 {
+		return errors.New("npy: header too long")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(magic)
+	buf.Write([]byte{1, 0})
+	buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(header))))
+	buf.WriteString(header)
+	for _, v := range data {
+		if unsafe.Sizeof(zero) == 4 {
+			buf.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(v))))
+		} else {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(float64(v))))
+		}
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+var (
+	descrPattern   = regexp.MustCompile(`'descr'\s*:\s*'([<>|=])([fiu])(\d+)'`)
+	fortranPattern = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	shapePattern   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// Read reads a .npy array of floating-point or integer values, converting them to float64.
+// Arrays in Fortran order are returned in row-major order.
+func Read(r io.Reader) (*Array, error) {
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:6], magic) {
+		return nil, errors.New("npy: not a .npy file")
+	}
+
+	var headerLen int
+	switch prefix[6] {
+	case 1:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		headerLen = int(binary.LittleEndian.Uint16(b))
+	case 2, 3:
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		headerLen = int(binary.LittleEndian.Uint32(b))
+	default:
+		return nil, fmt.Errorf("npy: unsupported version %d.%d", prefix[6], prefix[7])
+	}
+	// read what is there rather than trusting the header length with a large allocation
+	header, err := io.ReadAll(io.LimitReader(r, int64(headerLen)))
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < headerLen {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	descr := descrPattern.FindSubmatch(header)
+	fortran := fortranPattern.FindSubmatch(header)
+	shape := shapePattern.FindSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return nil, fmt.Errorf("npy: unsupported header %q", header)
+	}
+
+	a := &Array{}
+	size := 1
+	for _, d := range strings.Split(string(shape[1]), ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("npy: invalid shape (%s)", shape[1])
+		}
+		if n > 0 && size > maxSize/n {
+			return nil, fmt.Errorf("npy: shape (%s) too large", shape[1])
+		}
+		a.Shape = append(a.Shape, n)
+		size *= n
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if descr[1][0] == '>' {
+		order = binary.BigEndian
+	}
+	kind := descr[2][0]
+	width, _ := strconv.Atoi(string(descr[3]))
+	decode, err := decoder(kind, width, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// read in chunks, so that a truncated array fails before its size is allocated
+	a.Data = make([]float64, 0, min(size, chunk))
+	buf := make([]byte, min(size, chunk)*width)
+	for len(a.Data) < size {
+		b := buf[:min(size-len(a.Data), chunk)*width]
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("npy: reading %d values: %w", size, err)
+		}
+		for i := 0; i < len(b); i += width {
+			a.Data = append(a.Data, decode(b[i:]))
+		}
+	}
+
+	if string(fortran[1]) == "True" {
+		a.Data = fromFortran(a.Shape, a.Data)
+	}
+	return a, nil
+}
+
+func decoder(kind byte, width int, order binary.ByteOrder) (func([]byte) float64, error) {
+	switch {
+	case kind == 'f' && width == 4:
+		return func(b []byte) float64 { return float64(math.Float32frombits(order.Uint32(b))) }, nil
+	case kind == 'f' && width == 8:
+		return func(b []byte) float64 { return math.Float64frombits(order.Uint64(b)) }, nil
+	case kind == 'i' && width == 1:
+		return func(b []byte) float64 { return float64(int8(b[0])) }, nil
+	case kind == 'i' && width == 2:
+		return func(b []byte) float64 { return float64(int16(order.Uint16(b))) }, nil
+	case kind == 'i' && width == 4:
+		return func(b []byte) float64 { return float64(int32(order.Uint32(b))) }, nil
+	case kind == 'i' && width == 8:
+		return func(b []byte) float64 { return float64(int64(order.Uint64(b))) }, nil
+	case kind == 'u' && width == 1:
+		return func(b []byte) float64 { return float64(b[0]) }, nil
+	case kind == 'u' && width == 2:
+		return func(b []byte) float64 { return float64(order.Uint16(b)) }, nil
+	case kind == 'u' && width == 4:
+		return func(b []byte) float64 { return float64(order.Uint32(b)) }, nil
+	case kind == 'u' && width == 8:
+		return func(b []byte) float64 { return float64(order.Uint64(b)) }, nil
+	}
+	return nil, fmt.Errorf("npy: unsupported dtype %c%d", kind, width)
+}
+
+// fromFortran reorders column-major data into row-major order.
+func fromFortran(shape []int, data []float64) []float64 {
+	result := make([]float64, len(data))
+	index := make([]int, len(shape))
+	for i := range data {
+		// i is the row-major position of index, find its column-major position
+		pos, stride := 0, 1
+		for d := range shape {
+			pos += index[d] * stride
+			stride *= shape[d]
+		}
+		result[i] = data[pos]
+		for d := len(shape) - 1; d >= 0; d-- {
+			index[d]++
+			if index[d] < shape[d] {
+				break
+			}
+			index[d] = 0
+		}
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package npy
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Archives hold two arrays per layer, layer 0 being the first hidden layer:
+//
+//	weights_<layer>  (nodes, inputs), so that outputs = activation(inputs @ weights.T + biases)
+//	biases_<layer>   (nodes,)
+//
+// as written by numpy.savez(file, weights_0=..., biases_0=..., ...).
+
+func weightsName(l int) string {
+	return fmt.Sprintf("weights_%d", l)
+}
+
+func biasesName(l int) string {
+	return fmt.Sprintf("biases_%d", l)
+}
+
+// Save writes the weights and biases of every layer of the network as an .npz archive.
+func Save[T fns.Float](w io.Writer, nn *feedforward.Net[T]) error {
+	zw := zip.NewWriter(w)
+	shapes := nn.Shapes()
+	for l := range len(shapes) - 1 {
+		f, err := zw.Create(weightsName(l) + ".npy")
+		if err != nil {
+			return err
+		}
+		if err := Write(f, []int{shapes[l+1], shapes[l]}, nn.Weights(l)); err != nil {
+			return err
+		}
+		f, err = zw.Create(biasesName(l) + ".npy")
+		if err != nil {
+			return err
+		}
+		if err := Write(f, []int{shapes[l+1]}, nn.Biases(l)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Load builds a network from an .npz archive of weights and biases.
+// Shapes are taken from the arrays; options such as the activation are applied on top.
+// Passing feedforward.Shapes validates the archive against them.
+func Load(r io.ReaderAt, size int64, opt ...feedforward.NetworkOpt) (*feedforward.Network, error) {
+	arrays, err := readArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, ok := arrays[weightsName(0)]
+	if !ok || len(weights.Shape) != 2 {
+		return nil, fmt.Errorf("npy: archive has no %s matrix", weightsName(0))
+	}
+	shapes := []int{weights.Shape[1]}
+	for l := 0; ; l++ {
+		weights, ok := arrays[weightsName(l)]
+		if !ok {
+			break
+		}
+		if len(weights.Shape) != 2 {
+			return nil, fmt.Errorf("npy: %s has shape %v, want a matrix", weightsName(l), weights.Shape)
+		}
+		shapes = append(shapes, weights.Shape[0])
+	}
+
+	nn := feedforward.New(append([]feedforward.NetworkOpt{feedforward.Shapes(shapes)}, opt...)...)
+	if err := overwrite(nn, arrays); err != nil {
+		return nil, err
+	}
+	return nn, nil
+}
+
+// Overwrite replaces the weights and biases of the network with those of an .npz archive.
+// Every array must match the shapes of the network. Pruned weights stay zero.
+func Overwrite[T fns.Float](nn *feedforward.Net[T], r io.ReaderAt, size int64) error {
+	arrays, err := readArchive(r, size)
+	if err != nil {
+		return err
+	}
+	return overwrite(nn, arrays)
+}
+
+func overwrite[T fns.Float](nn *feedforward.Net[T], arrays map[string]*Array) error {
+	shapes := nn.Shapes()
+	layers := len(shapes) - 1
+	if _, ok := arrays[weightsName(layers)]; ok {
+		return fmt.Errorf("npy: archive has more than %d layers", layers)
+	}
+
+	for l := range layers {
+		weights, ok := arrays[weightsName(l)]
+		if !ok {
+			return fmt.Errorf("npy: archive has no %s", weightsName(l))
+		}
+		if want := []int{shapes[l+1], shapes[l]}; !slices.Equal(weights.Shape, want) {
+			return fmt.Errorf("npy: %s has shape %v, want %v", weightsName(l), weights.Shape, want)
+		}
+		biases, ok := arrays[biasesName(l)]
+		if !ok {
+			return fmt.Errorf("npy: archive has no %s", biasesName(l))
+		}
+		if want := []int{shapes[l+1]}; !slices.Equal(biases.Shape, want) {
+			return fmt.Errorf("npy: %s has shape %v, want %v", biasesName(l), biases.Shape, want)
+		}
+	}
+
+	for l := range layers {
+		err := nn.SetWeights(l, fns.Convert[T](arrays[weightsName(l)].Data), fns.Convert[T](arrays[biasesName(l)].Data))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readArchive reads every .npy array of an .npz archive by name, without extension.
+func readArchive(r io.ReaderAt, size int64) (map[string]*Array, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	arrays := map[string]*Array{}
+	for _, f := range zr.File {
+		name, ok := strings.CutSuffix(f.Name, ".npy")
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		a, err := Read(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		arrays[name] = a
+	}
+	return arrays, nil
+}
@@ -0,0 +1,378 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Subset of the ONNX messages, see https://github.com/onnx/onnx/blob/main/onnx/onnx.proto
+
+// TensorProto data types
+const (
+	typeFloat  = 1
+	typeDouble = 11
+)
+
+// AttributeProto types
+const (
+	attrFloat = 1
+	attrInt   = 2
+)
+
+type model struct {
+	irVersion    int64
+	opsetVersion int64
+	producer     string
+	graph        *graph
+}
+
+type graph struct {
+	name         string
+	nodes        []*node
+	initializers []*tensor
+	inputs       []*valueInfo
+	outputs      []*valueInfo
+}
+
+type node struct {
+	name    string
+	opType  string
+	inputs  []string
+	outputs []string
+	attrs   []*attribute
+}
+
+// attr returns the attribute with the given name, nil if the node has none.
+func (n *node) attr(name string) *attribute {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+type attribute struct {
+	name string
+	typ  int64
+	f    float64
+	i    int64
+}
+
+type tensor struct {
+	name     string
+	dims     []int64
+	dataType int64
+	data     []float64
+}
+
+// valueInfo describes a graph input or output tensor. Dimensions of -1 are symbolic.
+type valueInfo struct {
+	name     string
+	elemType int64
+	dims     []int64
+}
+
+func (m *model) marshal() []byte {
+	e := &encoder{}
+	e.varint(1, m.irVersion)
+	e.string(2, m.producer)
+	e.message(7, m.graph.marshal)
+	e.message(8, func(e *encoder) {
+		e.string(1, "")
+		e.varint(2, m.opsetVersion)
+	})
+	return e.buf
+}
+
+func (g *graph) marshal(e *encoder) {
+	for _, n := range g.nodes {
+		e.message(1, n.marshal)
+	}
+	e.string(2, g.name)
+	for _, t := range g.initializers {
+		e.message(5, t.marshal)
+	}
+	for _, v := range g.inputs {
+		e.message(11, v.marshal)
+	}
+	for _, v := range g.outputs {
+		e.message(12, v.marshal)
+	}
+}
+
+func (n *node) marshal(e *encoder) {
+	for _, v := range n.inputs {
+		e.string(1, v)
+	}
+	for _, v := range n.outputs {
+		e.string(2, v)
+	}
+	e.string(3, n.name)
+	e.string(4, n.opType)
+	for _, a := range n.attrs {
+		e.message(5, a.marshal)
+	}
+}
+
+func (a *attribute) marshal(e *encoder) {
+	e.string(1, a.name)
+	switch a.typ {
+	case attrFloat:
+		e.float(2, float32(a.f))
+	case attrInt:
+		e.varint(3, a.i)
+	}
+	e.varint(20, a.typ)
+}
+
+func (t *tensor) marshal(e *encoder) {
+	for _, d := range t.dims {
+		e.varint(1, d)
+	}
+	e.varint(2, t.dataType)
+	e.string(8, t.name)
+	var raw []byte
+	for _, v := range t.data {
+		if t.dataType == typeFloat {
+			raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(float32(v)))
+		} else {
+			raw = binary.LittleEndian.AppendUint64(raw, math.Float64bits(v))
+		}
+	}
+	e.bytes(9, raw)
+}
+
+func (v *valueInfo) marshal(e *encoder) {
+	e.string(1, v.name)
+	e.message(2, func(e *encoder) {
+		e.message(1, func(e *encoder) {
+			e.varint(1, v.elemType)
+			e.message(2, func(e *encoder) {
+				for _, d := range v.dims {
+					e.message(1, func(e *encoder) {
+						if d < 0 {
+							e.string(2, "N")
+						} else {
+							e.varint(1, d)
+						}
+					})
+				}
+			})
+		})
+	})
+}
+
+func unmarshalModel(b []byte) (*model, error) {
+	m := &model{}
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.irVersion = int64(f.v)
+		case 2:
+			m.producer = string(f.data)
+		case 7:
+			g, err := unmarshalGraph(f.data)
+			if err != nil {
+				return err
+			}
+			m.graph = g
+		case 8:
+			var domain string
+			var version int64
+			err := decode(f.data, func(f field) error {
+				switch f.num {
+				case 1:
+					domain = string(f.data)
+				case 2:
+					version = int64(f.v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if domain == "" || domain == "ai.onnx" {
+				m.opsetVersion = version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m.graph == nil {
+		return nil, fmt.Errorf("onnx: model has no graph")
+	}
+	return m, nil
+}
+
+func unmarshalGraph(b []byte) (*graph, error) {
+	g := &graph{}
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			n, err := unmarshalNode(f.data)
+			if err != nil {
+				return err
+			}
+			g.nodes = append(g.nodes, n)
+		case 2:
+			g.name = string(f.data)
+		case 5:
+			t, err := unmarshalTensor(f.data)
+			if err != nil {
+				return err
+			}
+			g.initializers = append(g.initializers, t)
+		case 11, 12:
+			v, err := unmarshalValueInfo(f.data)
+			if err != nil {
+				return err
+			}
+			if f.num == 11 {
+				g.inputs = append(g.inputs, v)
+			} else {
+				g.outputs = append(g.outputs, v)
+			}
+		}
+		return nil
+	})
+	return g, err
+}
+
+func unmarshalNode(b []byte) (*node, error) {
+	n := &node{}
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			n.inputs = append(n.inputs, string(f.data))
+		case 2:
+			n.outputs = append(n.outputs, string(f.data))
+		case 3:
+			n.name = string(f.data)
+		case 4:
+			n.opType = string(f.data)
+		case 5:
+			a := &attribute{}
+			err := decode(f.data, func(f field) error {
+				switch f.num {
+				case 1:
+					a.name = string(f.data)
+				case 2:
+					a.f = float64(math.Float32frombits(uint32(f.v)))
+				case 3:
+					a.i = int64(f.v)
+				case 20:
+					a.typ = int64(f.v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			n.attrs = append(n.attrs, a)
+		}
+		return nil
+	})
+	return n, err
+}
+
+func unmarshalTensor(b []byte) (*tensor, error) {
+	t := &tensor{}
+	var raw []byte
+	err := decode(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			t.dims, err = f.int64s(t.dims)
+		case 2:
+			t.dataType = int64(f.v)
+		case 4:
+			t.data, err = f.floats(t.data)
+		case 8:
+			t.name = string(f.data)
+		case 9:
+			raw = f.data
+		case 10:
+			t.data, err = f.doubles(t.data)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if raw != nil {
+		var err error
+		switch t.dataType {
+		case typeFloat:
+			t.data, err = field{wire: wireBytes, data: raw}.floats(nil)
+		case typeDouble:
+			t.data, err = field{wire: wireBytes, data: raw}.doubles(nil)
+		default:
+			err = fmt.Errorf("onnx: tensor %s has unsupported data type %d", t.name, t.dataType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	size := int64(1)
+	for _, d := range t.dims {
+		if d <= 0 {
+			return nil, fmt.Errorf("onnx: tensor %s has invalid dimensions %v", t.name, t.dims)
+		}
+		// stop before the product can overflow
+		if size *= d; size > int64(len(t.data)) {
+			break
+		}
+	}
+	if size != int64(len(t.data)) {
+		return nil, fmt.Errorf("onnx: tensor %s has %d values, want %d", t.name, len(t.data), size)
+	}
+	return t, nil
+}
+
+func unmarshalValueInfo(b []byte) (*valueInfo, error) {
+	v := &valueInfo{}
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			v.name = string(f.data)
+		case 2:
+			// TypeProto.tensor_type
+			return decode(f.data, func(f field) error {
+				if f.num != 1 {
+					return nil
+				}
+				return decode(f.data, func(f field) error {
+					switch f.num {
+					case 1:
+						v.elemType = int64(f.v)
+					case 2:
+						// TensorShapeProto.dim
+						return decode(f.data, func(f field) error {
+							if f.num != 1 {
+								return nil
+							}
+							d := int64(-1)
+							err := decode(f.data, func(f field) error {
+								if f.num == 1 {
+									d = int64(f.v)
+								}
+								return nil
+							})
+							v.dims = append(v.dims, d)
+							return err
+						})
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+	return v, err
+}
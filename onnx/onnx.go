@@ -0,0 +1,258 @@
+// Package onnx exports feedforward networks to ONNX and imports ONNX MLP graphs.
+//
+// A network maps to a chain of Gemm nodes, one per layer, each followed by an
+// activation node (Sigmoid, Tanh or Relu, none for linear layers).
+// Imported graphs must have that form with the same activation on every layer.
+package onnx
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"unsafe"
+)
+
+const (
+	irVersion    = 8
+	opsetVersion = 13
+)
+
+// operators maps activation names to ONNX operators.
+var operators = map[string]string{
+	"sigmoid": "Sigmoid",
+	"tanh":    "Tanh",
+	"relu":    "Relu",
+	"linear":  "",
+}
+
+// Export writes the network as an ONNX model with input "input" and output "output",
+// both of shape [N, features]. Weights are stored as float or double, matching T.
+// The activation must have been set with feedforward.NamedActivation.
+func Export[T fns.Float](w io.Writer, nn *feedforward.Net[T]) error {
+	activation := nn.ActivationName()
+	op, ok := operators[activation]
+	if !ok {
+		return fmt.Errorf("onnx: activation %q has no ONNX operator, set it with feedforward.NamedActivation", activation)
+	}
+
+	var zero T
+	dataType := int64(typeDouble)
+	if unsafe.Sizeof(zero) == 4 {
+		dataType = typeFloat
+	}
+
+	shapes := nn.Shapes()
+	g := &graph{
+		name:    "gonet",
+		inputs:  []*valueInfo{{name: "input", elemType: dataType, dims: []int64{-1, int64(shapes[0])}}},
+		outputs: []*valueInfo{{name: "output", elemType: dataType, dims: []int64{-1, int64(shapes[len(shapes)-1])}}},
+	}
+
+	x := "input"
+	last := len(shapes) - 2
+	for l := range len(shapes) - 1 {
+		in, out := int64(shapes[l]), int64(shapes[l+1])
+		weights := fmt.Sprintf("W%d", l)
+		biases := fmt.Sprintf("B%d", l)
+		g.initializers = append(g.initializers,
+			&tensor{name: weights, dims: []int64{out, in}, dataType: dataType, data: fns.Convert[float64](nn.Weights(l))},
+			&tensor{name: biases, dims: []int64{out}, dataType: dataType, data: fns.Convert[float64](nn.Biases(l))},
+		)
+
+		y := fmt.Sprintf("gemm%d", l)
+		if l == last && op == "" {
+			y = "output"
+		}
+		g.nodes = append(g.nodes, &node{
+			name:    y,
+			opType:  "Gemm",
+			inputs:  []string{x, weights, biases},
+			outputs: []string{y},
+			attrs:   []*attribute{{name: "transB", typ: attrInt, i: 1}},
+		})
+		x = y
+
+		if op != "" {
+			y = fmt.Sprintf("layer%d", l)
+			if l == last {
+				y = "output"
+			}
+			g.nodes = append(g.nodes, &node{
+				name:    y,
+				opType:  op,
+				inputs:  []string{x},
+				outputs: []string{y},
+			})
+			x = y
+		}
+	}
+
+	m := &model{
+		irVersion:    irVersion,
+		opsetVersion: opsetVersion,
+		producer:     "gonet",
+		graph:        g,
+	}
+	_, err := w.Write(m.marshal())
+	return err
+}
+
+// Import reads an ONNX MLP graph into a network.
+// Options are applied after the shapes and activation found in the graph,
+// e.g. to set a learning rate for further training.
+func Import(r io.Reader, opt ...feedforward.NetworkOpt) (*feedforward.Network, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := unmarshalModel(b)
+	if err != nil {
+		return nil, err
+	}
+
+	initializers := map[string]*tensor{}
+	for _, t := range m.graph.initializers {
+		initializers[t.name] = t
+	}
+
+	// the graph input is the one input that isn't an initializer
+	var x string
+	for _, v := range m.graph.inputs {
+		if _, ok := initializers[v.name]; !ok {
+			x = v.name
+			break
+		}
+	}
+	if x == "" {
+		return nil, fmt.Errorf("onnx: graph has no input")
+	}
+
+	var layers []*layer
+	for _, n := range m.graph.nodes {
+		if len(n.inputs) == 0 || n.inputs[0] != x || len(n.outputs) != 1 {
+			return nil, fmt.Errorf("onnx: node %s (%s) is not part of a sequential graph", n.name, n.opType)
+		}
+		x = n.outputs[0]
+
+		switch n.opType {
+		case "Gemm":
+			l, err := gemm(n, initializers)
+			if err != nil {
+				return nil, err
+			}
+			if len(layers) > 0 && layers[len(layers)-1].out != l.in {
+				return nil, fmt.Errorf("onnx: node %s has %d inputs, previous layer has %d outputs", n.name, l.in, layers[len(layers)-1].out)
+			}
+			layers = append(layers, l)
+		case "Identity":
+		default:
+			activation := ""
+			for name, op := range operators {
+				if op == n.opType && op != "" {
+					activation = name
+				}
+			}
+			if activation == "" {
+				return nil, fmt.Errorf("onnx: node %s has unsupported operator %s", n.name, n.opType)
+			}
+			if len(layers) == 0 || layers[len(layers)-1].activation != "linear" {
+				return nil, fmt.Errorf("onnx: node %s (%s) doesn't follow a Gemm node", n.name, n.opType)
+			}
+			layers[len(layers)-1].activation = activation
+		}
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("onnx: graph has no Gemm node")
+	}
+	if len(m.graph.outputs) != 1 || m.graph.outputs[0].name != x {
+		return nil, fmt.Errorf("onnx: graph output doesn't match %s, the output of its last node", x)
+	}
+
+	shapes := []int{layers[0].in}
+	for _, l := range layers {
+		if l.activation != layers[0].activation {
+			return nil, fmt.Errorf("onnx: layers mix %s and %s activations", layers[0].activation, l.activation)
+		}
+		shapes = append(shapes, l.out)
+	}
+
+	nn := feedforward.New(append([]feedforward.NetworkOpt{
+		feedforward.Shapes(shapes),
+		feedforward.NamedActivation(layers[0].activation),
+	}, opt...)...)
+	for i, l := range layers {
+		copy(nn.Weights(i), l.weights)
+		copy(nn.Biases(i), l.biases)
+	}
+	return nn, nil
+}
+
+type layer struct {
+	in, out    int
+	weights    []float64 // out x in
+	biases     []float64
+	activation string
+}
+
+// gemm converts Y = alpha * X * W' + beta * B into a dense layer, W' being W or its transpose.
+func gemm(n *node, initializers map[string]*tensor) (*layer, error) {
+	if a := n.attr("transA"); a != nil && a.i != 0 {
+		return nil, fmt.Errorf("onnx: node %s transposes its input", n.name)
+	}
+	alpha, beta := 1.0, 1.0
+	if a := n.attr("alpha"); a != nil {
+		alpha = a.f
+	}
+	if a := n.attr("beta"); a != nil {
+		beta = a.f
+	}
+
+	if len(n.inputs) < 2 {
+		return nil, fmt.Errorf("onnx: node %s has no weights", n.name)
+	}
+	w, ok := initializers[n.inputs[1]]
+	if !ok || len(w.dims) != 2 {
+		return nil, fmt.Errorf("onnx: node %s weights %s are not a constant matrix", n.name, n.inputs[1])
+	}
+
+	l := &layer{activation: "linear"}
+	if a := n.attr("transB"); a != nil && a.i != 0 {
+		l.out, l.in = int(w.dims[0]), int(w.dims[1])
+		l.weights = make([]float64, len(w.data))
+		for i, v := range w.data {
+			l.weights[i] = alpha * v
+		}
+	} else {
+		l.in, l.out = int(w.dims[0]), int(w.dims[1])
+		l.weights = make([]float64, len(w.data))
+		for i := range l.in {
+			for j := range l.out {
+				l.weights[j*l.in+i] = alpha * w.data[i*l.out+j]
+			}
+		}
+	}
+
+	l.biases = make([]float64, l.out)
+	if len(n.inputs) > 2 && n.inputs[2] != "" {
+		b, ok := initializers[n.inputs[2]]
+		if !ok {
+			return nil, fmt.Errorf("onnx: node %s biases %s are not constant", n.name, n.inputs[2])
+		}
+		switch len(b.data) {
+		case l.out:
+			for j, v := range b.data {
+				l.biases[j] = beta * v
+			}
+		case 1:
+			// broadcast
+			for j := range l.biases {
+				l.biases[j] = beta * b.data[0]
+			}
+		default:
+			return nil, fmt.Errorf("onnx: node %s has %d biases, want %d", n.name, len(b.data), l.out)
+		}
+	}
+
+	return l, nil
+}
@@ -0,0 +1,142 @@
+package onnx
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/lnashier/gonet/feedforward"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Fixtures are written by testdata/gen.py, along with inputs and their expected outputs.
+func TestImportFixtures(t *testing.T) {
+	for _, name := range []string{"mlp_sigmoid_float", "mlp_tanh_double"} {
+		t.Run(name, func(t *testing.T) {
+			nn := importFile(t, filepath.Join("testdata", name+".onnx"))
+
+			b, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected struct {
+				Inputs, Outputs [][]float64
+			}
+			if err := json.Unmarshal(b, &expected); err != nil {
+				t.Fatal(err)
+			}
+			for i, x := range expected.Inputs {
+				got, want := nn.Predict(x), expected.Outputs[i]
+				for j := range want {
+					if math.Abs(got[j]-want[j]) > 1e-12 {
+						t.Fatalf("input %d output %d: got %v, want %v", i, j, got[j], want[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	rand.Seed(1)
+	nn := feedforward.New(feedforward.Shapes([]int{5, 4, 3, 2}), feedforward.NamedActivation("tanh"))
+	nn32 := feedforward.Convert[float32](nn)
+
+	t.Run("float64", func(t *testing.T) {
+		assertSame(t, roundTrip(t, nn), nn)
+	})
+	t.Run("float32", func(t *testing.T) {
+		assertSame(t, roundTrip(t, nn32), feedforward.Convert[float64](nn32))
+	})
+	t.Run("fixture", func(t *testing.T) {
+		nn := importFile(t, filepath.Join("testdata", "mlp_sigmoid_float.onnx"))
+		assertSame(t, roundTrip(t, nn), nn)
+	})
+}
+
+func TestImportInvalid(t *testing.T) {
+	tests := map[string]func(m *model){
+		"negative dimensions": func(m *model) {
+			w := m.graph.initializers[0]
+			w.dims = []int64{-w.dims[0], -w.dims[1]}
+		},
+		"zero dimension": func(m *model) {
+			w := m.graph.initializers[0]
+			w.dims = []int64{0, w.dims[1]}
+			w.data = nil
+		},
+		"output name": func(m *model) {
+			m.graph.outputs[0].name = "gemm1"
+		},
+		"missing output": func(m *model) {
+			m.graph.outputs = nil
+		},
+	}
+
+	rand.Seed(1)
+	nn := feedforward.New(feedforward.Shapes([]int{3, 2, 1}), feedforward.NamedActivation("sigmoid"))
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, nn); err != nil {
+				t.Fatal(err)
+			}
+			m, err := unmarshalModel(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			mutate(m)
+			_, err = Import(bytes.NewReader(m.marshal()))
+			if err == nil {
+				t.Fatal("imported an invalid model")
+			}
+			t.Log(err)
+		})
+	}
+}
+
+func importFile(t *testing.T, name string) *feedforward.Network {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	nn, err := Import(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nn
+}
+
+func roundTrip[T float32 | float64](t *testing.T, nn *feedforward.Net[T]) *feedforward.Network {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Export(&buf, nn); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := Import(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return imported
+}
+
+func assertSame(t *testing.T, got, want *feedforward.Network) {
+	t.Helper()
+	if !slices.Equal(got.Shapes(), want.Shapes()) || got.ActivationName() != want.ActivationName() {
+		t.Fatalf("got %v %s, want %v %s", got.Shapes(), got.ActivationName(), want.Shapes(), want.ActivationName())
+	}
+	for l := range len(want.Shapes()) - 1 {
+		for _, p := range [][2][]float64{{got.Weights(l), want.Weights(l)}, {got.Biases(l), want.Biases(l)}} {
+			for i := range p[1] {
+				if math.Float64bits(p[0][i]) != math.Float64bits(p[1][i]) {
+					t.Fatalf("layer %d value %d: got %v, want %v", l, i, p[0][i], p[1][i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Minimal protocol buffers wire format, enough for the messages of an ONNX MLP graph.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(num, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(num)<<3|uint64(wire))
+}
+
+func (e *encoder) varint(num int, v int64) {
+	e.tag(num, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+func (e *encoder) float(num int, v float32) {
+	e.tag(num, wireFixed32)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(v))
+}
+
+func (e *encoder) bytes(num int, v []byte) {
+	e.tag(num, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) string(num int, v string) {
+	e.bytes(num, []byte(v))
+}
+
+// message encodes a nested message built by fn.
+func (e *encoder) message(num int, fn func(*encoder)) {
+	m := &encoder{}
+	fn(m)
+	e.bytes(num, m.buf)
+}
+
+// field is a decoded field: v holds varint and fixed values, data holds length-delimited ones.
+type field struct {
+	num  int
+	wire int
+	v    uint64
+	data []byte
+}
+
+var errTruncated = errors.New("onnx: truncated message")
+
+// decode calls fn for every field of the message in b.
+func decode(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		f := field{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			f.v = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			f.v = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return errTruncated
+			}
+			f.data = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			return fmt.Errorf("onnx: unsupported wire type %d", f.wire)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// int64s appends the values of a repeated int64 field, packed or not.
+func (f field) int64s(dst []int64) ([]int64, error) {
+	if f.wire == wireVarint {
+		return append(dst, int64(f.v)), nil
+	}
+	b := f.data
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		dst = append(dst, int64(v))
+		b = b[n:]
+	}
+	return dst, nil
+}
+
+// floats appends the values of a repeated float field, packed or not.
+func (f field) floats(dst []float64) ([]float64, error) {
+	if f.wire == wireFixed32 {
+		return append(dst, float64(math.Float32frombits(uint32(f.v)))), nil
+	}
+	if len(f.data)%4 != 0 {
+		return nil, errTruncated
+	}
+	for i := 0; i < len(f.data); i += 4 {
+		dst = append(dst, float64(math.Float32frombits(binary.LittleEndian.Uint32(f.data[i:]))))
+	}
+	return dst, nil
+}
+
+// doubles appends the values of a repeated double field, packed or not.
+func (f field) doubles(dst []float64) ([]float64, error) {
+	if f.wire == wireFixed64 {
+		return append(dst, math.Float64frombits(f.v)), nil
+	}
+	if len(f.data)%8 != 0 {
+		return nil, errTruncated
+	}
+	for i := 0; i < len(f.data); i += 8 {
+		dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(f.data[i:])))
+	}
+	return dst, nil
+}
@@ -0,0 +1,260 @@
+#!/usr/bin/env python3
+"""Generates the ONNX fixtures of the onnx package tests.
+
+Models are built with onnx.helper when the onnx package is installed, the way
+exporters such as torch.onnx write them. Without it, the same messages are
+serialized by the minimal protobuf writer below, in the canonical field order
+of the protobuf runtime, so both paths write the same bytes.
+
+Every model comes with a JSON file of inputs and the outputs computed here.
+
+    python3 gen.py
+"""
+
+import json
+import math
+import random
+import struct
+
+FLOAT, DOUBLE = 1, 11
+ATTR_FLOAT, ATTR_INT = 1, 2
+
+
+def f32(v):
+    return struct.unpack("<f", struct.pack("<f", v))[0]
+
+
+# Fixtures, each a dict of model fields, weights and activation.
+
+
+def mlp_sigmoid_float():
+    """A torch.nn.Sequential of Linear and Sigmoid layers as torch.onnx.export writes it:
+    float32 raw data, transposed weights, dynamic batch dimension."""
+    rng = random.Random(1)
+    shapes = [4, 6, 3]
+    nodes, inits = [], []
+    x = "input"
+    for l in range(len(shapes) - 1):
+        n_in, n_out = shapes[l], shapes[l + 1]
+        w = [[f32(rng.uniform(-1, 1)) for _ in range(n_in)] for _ in range(n_out)]
+        b = [f32(rng.uniform(-1, 1)) for _ in range(n_out)]
+        inits.append(("%d.weight" % (2 * l), FLOAT, [n_out, n_in], sum(w, []), True))
+        inits.append(("%d.bias" % (2 * l), FLOAT, [n_out], b, True))
+        y = "/%d/Gemm_output_0" % (2 * l)
+        nodes.append(("/%d/Gemm" % (2 * l), "Gemm", [x, "%d.weight" % (2 * l), "%d.bias" % (2 * l)], [y],
+                      [("alpha", ATTR_FLOAT, 1.0), ("beta", ATTR_FLOAT, 1.0), ("transB", ATTR_INT, 1)]))
+        x = y
+        y = "output" if l == len(shapes) - 2 else "/%d/Sigmoid_output_0" % (2 * l + 1)
+        nodes.append(("/%d/Sigmoid" % (2 * l + 1), "Sigmoid", [x], [y], []))
+        x = y
+    return dict(
+        producer=("pytorch", "2.1.0"), ir=8, opset=17, graph="main_graph", elem=FLOAT,
+        nodes=nodes, inits=inits, extra_inputs=False,
+        inputs=("input", ["batch_size", shapes[0]]), outputs=("output", ["batch_size", shapes[-1]]),
+    )
+
+
+def mlp_tanh_double():
+    """A hand-built graph as onnx.helper users write them: double weights in double_data,
+    untransposed weights, alpha and beta, a broadcast bias, initializers listed as graph inputs
+    like IR version 3 requires, and a trailing Identity node."""
+    rng = random.Random(2)
+    shapes = [3, 5, 2]
+    nodes, inits = [], []
+    x = "X"
+    for l in range(len(shapes) - 1):
+        n_in, n_out = shapes[l], shapes[l + 1]
+        w = [[rng.uniform(-1, 1) for _ in range(n_out)] for _ in range(n_in)]
+        b = [rng.uniform(-1, 1)]
+        inits.append(("W%d" % l, DOUBLE, [n_in, n_out], sum(w, []), False))
+        inits.append(("B%d" % l, DOUBLE, [1], b, False))
+        nodes.append(("gemm%d" % l, "Gemm", [x, "W%d" % l, "B%d" % l], ["h%d" % l],
+                      [("alpha", ATTR_FLOAT, 0.5), ("beta", ATTR_FLOAT, 2.0)]))
+        nodes.append(("tanh%d" % l, "Tanh", ["h%d" % l], ["a%d" % l], []))
+        x = "a%d" % l
+    nodes.append(("identity", "Identity", [x], ["Y"], []))
+    return dict(
+        producer=("onnx.helper", ""), ir=3, opset=9, graph="mlp", elem=DOUBLE,
+        nodes=nodes, inits=inits, extra_inputs=True,
+        inputs=("X", [None, shapes[0]]), outputs=("Y", [None, shapes[-1]]),
+    )
+
+
+FIXTURES = {
+    "mlp_sigmoid_float": (mlp_sigmoid_float, lambda v: 1 / (1 + math.exp(-v))),
+    "mlp_tanh_double": (mlp_tanh_double, math.tanh),
+}
+
+
+# Reference predictions.
+
+
+def predict(m, af, x):
+    inits = {name: (dims, data) for name, _, dims, data, _ in m["inits"]}
+    for name, op, inputs, _, attrs in m["nodes"]:
+        if op != "Gemm":
+            if op != "Identity":
+                x = [af(v) for v in x]
+            continue
+        attrs = {a[0]: a[2] for a in attrs}
+        alpha, beta = attrs.get("alpha", 1.0), attrs.get("beta", 1.0)
+        dims, w = inits[inputs[1]]
+        _, b = inits[inputs[2]]
+        if attrs.get("transB", 0):
+            n_out, n_in = dims
+            weight = lambda j, i: w[j * n_in + i]
+        else:
+            n_in, n_out = dims
+            weight = lambda j, i: w[i * n_out + j]
+        y = []
+        for j in range(n_out):
+            s = sum(x[i] * weight(j, i) for i in range(n_in))
+            y.append(alpha * s + beta * (b[j] if len(b) > 1 else b[0]))
+        x = y
+    return x
+
+
+# Serialization with onnx.helper.
+
+
+def build_onnx(m):
+    import onnx
+    from onnx import helper
+
+    nodes = [helper.make_node(op, inputs, outputs, name=name, **{a[0]: a[2] for a in attrs})
+             for name, op, inputs, outputs, attrs in m["nodes"]]
+    inits = []
+    for name, typ, shape, data, raw in m["inits"]:
+        if raw:
+            fmt = "<%d%s" % (len(data), "f" if typ == FLOAT else "d")
+            inits.append(helper.make_tensor(name, typ, shape, struct.pack(fmt, *data), raw=True))
+        else:
+            inits.append(helper.make_tensor(name, typ, shape, data))
+    inputs = [helper.make_tensor_value_info(m["inputs"][0], m["elem"], m["inputs"][1])]
+    if m["extra_inputs"]:
+        inputs += [helper.make_tensor_value_info(name, typ, shape) for name, typ, shape, _, _ in m["inits"]]
+    outputs = [helper.make_tensor_value_info(m["outputs"][0], m["elem"], m["outputs"][1])]
+    graph = helper.make_graph(nodes, m["graph"], inputs, outputs, inits)
+    producer = {"producer_name": m["producer"][0]}
+    if m["producer"][1]:
+        producer["producer_version"] = m["producer"][1]
+    model = helper.make_model(graph, opset_imports=[helper.make_opsetid("", m["opset"])], **producer)
+    model.ir_version = m["ir"]
+    onnx.checker.check_model(model)
+    return model.SerializeToString()
+
+
+# Serialization without onnx, fields in field number order like the protobuf runtime.
+
+
+def varint(v):
+    v &= (1 << 64) - 1
+    out = bytearray()
+    while True:
+        b = v & 0x7F
+        v >>= 7
+        if v:
+            out.append(b | 0x80)
+        else:
+            out.append(b)
+            return bytes(out)
+
+
+def tag(num, wire):
+    return varint(num << 3 | wire)
+
+
+def f_varint(num, v):
+    return tag(num, 0) + varint(v)
+
+
+def f_bytes(num, v):
+    if isinstance(v, str):
+        v = v.encode()
+    return tag(num, 2) + varint(len(v)) + v
+
+
+def f_float(num, v):
+    return tag(num, 5) + struct.pack("<f", v)
+
+
+def attribute(name, typ, v):
+    out = f_bytes(1, name)
+    if typ == ATTR_FLOAT:
+        out += f_float(2, v)
+    else:
+        out += f_varint(3, v)
+    return out + f_varint(20, typ)
+
+
+def node(name, op, inputs, outputs, attrs):
+    out = b"".join(f_bytes(1, v) for v in inputs)
+    out += b"".join(f_bytes(2, v) for v in outputs)
+    out += f_bytes(3, name) + f_bytes(4, op)
+    # onnx.helper.make_node adds attributes sorted by name
+    out += b"".join(f_bytes(5, attribute(*a)) for a in sorted(attrs))
+    return out
+
+
+def tensor(name, typ, shape, data, raw):
+    # dims is an unpacked repeated field in onnx.proto (proto2)
+    out = b"".join(f_varint(1, d) for d in shape)
+    out += f_varint(2, typ)
+    if raw:
+        out += f_bytes(8, name)
+        out += f_bytes(9, struct.pack("<%d%s" % (len(data), "f" if typ == FLOAT else "d"), *data))
+    elif typ == FLOAT:
+        out += f_bytes(4, struct.pack("<%df" % len(data), *data)) + f_bytes(8, name)
+    else:
+        out += f_bytes(8, name) + f_bytes(10, struct.pack("<%dd" % len(data), *data))
+    return out
+
+
+def value_info(name, typ, shape):
+    dims = b""
+    for d in shape:
+        dims += f_bytes(1, f_bytes(2, d) if isinstance(d, str) else (b"" if d is None else f_varint(1, d)))
+    tensor_type = f_varint(1, typ) + f_bytes(2, dims)
+    return f_bytes(1, name) + f_bytes(2, f_bytes(1, tensor_type))
+
+
+def build_proto(m):
+    g = b"".join(f_bytes(1, node(*n)) for n in m["nodes"])
+    g += f_bytes(2, m["graph"])
+    g += b"".join(f_bytes(5, tensor(*t)) for t in m["inits"])
+    g += f_bytes(11, value_info(m["inputs"][0], m["elem"], m["inputs"][1]))
+    if m["extra_inputs"]:
+        g += b"".join(f_bytes(11, value_info(name, typ, shape)) for name, typ, shape, _, _ in m["inits"])
+    g += f_bytes(12, value_info(m["outputs"][0], m["elem"], m["outputs"][1]))
+
+    out = f_varint(1, m["ir"])
+    out += f_bytes(2, m["producer"][0])
+    if m["producer"][1]:
+        out += f_bytes(3, m["producer"][1])
+    out += f_bytes(7, g)
+    out += f_bytes(8, f_bytes(1, "") + f_varint(2, m["opset"]))
+    return out
+
+
+def main():
+    try:
+        build = build_onnx
+        import onnx  # noqa: F401
+    except ImportError:
+        build = build_proto
+
+    for name, (fixture, af) in FIXTURES.items():
+        m = fixture()
+        with open(name + ".onnx", "wb") as f:
+            f.write(build(m))
+
+        rng = random.Random(name)
+        n_in = m["inputs"][1][1]
+        inputs = [[rng.uniform(-2, 2) for _ in range(n_in)] for _ in range(5)]
+        with open(name + ".json", "w") as f:
+            json.dump({"inputs": inputs, "outputs": [predict(m, af, x) for x in inputs]}, f, indent=1)
+            f.write("\n")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,281 @@
+// Package plot draws line charts of training histories as SVG or PNG, in pure Go.
+package plot
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/stats"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// Series is a named line through points.
+type Series struct {
+	Name string
+	X    []float64
+	Y    []float64
+}
+
+// Chart is a line chart of series sharing axes.
+type Chart struct {
+	Title  string
+	XLabel string
+	Series []Series
+}
+
+// History returns a chart of the training and validation losses of a history,
+// followed by one of every metric.
+func History(t *stats.Training) []Chart {
+	loss := Chart{Title: "Loss", XLabel: "epoch"}
+	loss.Series = append(loss.Series, series(t, "train", func(e *stats.Epoch) (float64, bool) {
+		return e.TrainLoss, e.Inputs > 0
+	}))
+	if validation := series(t, "validation", func(e *stats.Epoch) (float64, bool) {
+		return e.ValidationLoss, e.ValidationInputs > 0
+	}); len(validation.X) > 0 {
+		loss.Series = append(loss.Series, validation)
+	}
+	charts := []Chart{loss}
+
+	var names []string
+	for _, e := range t.Epochs {
+		for name := range e.Metrics {
+			names = append(names, name)
+		}
+		for name := range e.ValidationMetrics {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		chart := Chart{Title: name, XLabel: "epoch"}
+		for _, s := range []Series{
+			series(t, "train", func(e *stats.Epoch) (float64, bool) {
+				v, ok := e.Metrics[name]
+				return v, ok
+			}),
+			series(t, "validation", func(e *stats.Epoch) (float64, bool) {
+				v, ok := e.ValidationMetrics[name]
+				return v, ok
+			}),
+		} {
+			if len(s.X) > 0 {
+				chart.Series = append(chart.Series, s)
+			}
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+func series(t *stats.Training, name string, value func(e *stats.Epoch) (float64, bool)) Series {
+	s := Series{Name: name}
+	for _, e := range t.Epochs {
+		if v, ok := value(e); ok {
+			s.X = append(s.X, float64(e.ID))
+			s.Y = append(s.Y, v)
+		}
+	}
+	return s
+}
+
+// SVG writes the charts stacked vertically, each width x height pixels.
+func SVG(w io.Writer, width, height int, charts ...Chart) error {
+	c := newSVG(width, height*len(charts))
+	for i, chart := range charts {
+		chart.draw(c, 0, float64(i*height), float64(width), float64(height))
+	}
+	return c.encode(w)
+}
+
+// PNG writes the charts like SVG, as an image.
+func PNG(w io.Writer, width, height int, charts ...Chart) error {
+	c := newRaster(width, height*len(charts))
+	for i, chart := range charts {
+		chart.draw(c, 0, float64(i*height), float64(width), float64(height))
+	}
+	return c.encode(w)
+}
+
+// Save writes the charts to the named file, as PNG when the name ends with .png and as SVG otherwise.
+func Save(name string, width, height int, charts ...Chart) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(name) == ".png" {
+		err = PNG(file, width, height, charts...)
+	} else {
+		err = SVG(file, width, height, charts...)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// canvas is what charts are drawn on. Coordinates are pixels from the top left corner,
+// text is centered vertically on y.
+type canvas interface {
+	rect(x, y, w, h float64, fill color.RGBA)
+	polyline(xs, ys []float64, stroke color.RGBA, width float64)
+	text(x, y float64, s string, anchor anchor, fill color.RGBA)
+	textWidth(s string) float64
+}
+
+type anchor int
+
+const (
+	start anchor = iota
+	middle
+	end
+)
+
+var (
+	white = color.RGBA{255, 255, 255, 255}
+	black = color.RGBA{0, 0, 0, 255}
+	gray  = color.RGBA{220, 220, 220, 255}
+	// palette of the series, cycled through
+	palette = []color.RGBA{
+		{31, 119, 180, 255},
+		{255, 127, 14, 255},
+		{44, 160, 44, 255},
+		{214, 39, 40, 255},
+		{148, 103, 189, 255},
+		{140, 86, 75, 255},
+		{227, 119, 194, 255},
+		{127, 127, 127, 255},
+	}
+)
+
+const (
+	marginLeft   = 70
+	marginRight  = 30
+	marginTop    = 36
+	marginBottom = 44
+	ticks        = 6
+)
+
+// draw draws the chart in the w x h box at x, y.
+func (chart Chart) draw(c canvas, x, y, w, h float64) {
+	c.rect(x, y, w, h, white)
+	c.text(x+w/2, y+marginTop/2, chart.Title, middle, black)
+
+	left, top := x+marginLeft, y+marginTop
+	pw, ph := w-marginLeft-marginRight, h-marginTop-marginBottom
+	if pw <= 0 || ph <= 0 {
+		return
+	}
+
+	x0, x1, y0, y1 := chart.bounds()
+	xs, ys := niceTicks(x0, x1), niceTicks(y0, y1)
+	if len(xs) > 1 {
+		x0, x1 = min(x0, xs[0]), max(x1, xs[len(xs)-1])
+	}
+	if len(ys) > 1 {
+		y0, y1 = min(y0, ys[0]), max(y1, ys[len(ys)-1])
+	}
+	px := func(v float64) float64 { return left + (v-x0)/(x1-x0)*pw }
+	py := func(v float64) float64 { return top + ph - (v-y0)/(y1-y0)*ph }
+
+	for _, v := range xs {
+		c.polyline([]float64{px(v), px(v)}, []float64{top, top + ph}, gray, 1)
+		c.text(px(v), top+ph+12, label(v), middle, black)
+	}
+	for _, v := range ys {
+		c.polyline([]float64{left, left + pw}, []float64{py(v), py(v)}, gray, 1)
+		c.text(left-6, py(v), label(v), end, black)
+	}
+	c.polyline([]float64{left, left, left + pw}, []float64{top, top + ph, top + ph}, black, 1)
+	c.text(left+pw/2, top+ph+32, chart.XLabel, middle, black)
+
+	for i, s := range chart.Series {
+		var lx, ly []float64
+		for j := range s.X {
+			if finite(s.X[j]) && finite(s.Y[j]) {
+				lx = append(lx, px(s.X[j]))
+				ly = append(ly, py(s.Y[j]))
+			}
+		}
+		c.polyline(lx, ly, palette[i%len(palette)], 2)
+	}
+
+	// legend in the top right corner
+	var legend float64
+	for _, s := range chart.Series {
+		legend = max(legend, c.textWidth(s.Name))
+	}
+	lx := left + pw - legend - 36
+	for i, s := range chart.Series {
+		ly := top + 12 + float64(i)*18
+		c.polyline([]float64{lx, lx + 20}, []float64{ly, ly}, palette[i%len(palette)], 2)
+		c.text(lx+26, ly, s.Name, start, black)
+	}
+}
+
+// bounds returns the ranges of the finite points of all series, never empty.
+func (chart Chart) bounds() (x0, x1, y0, y1 float64) {
+	x0, y0 = math.Inf(1), math.Inf(1)
+	x1, y1 = math.Inf(-1), math.Inf(-1)
+	for _, s := range chart.Series {
+		for i := range s.X {
+			if finite(s.X[i]) && finite(s.Y[i]) {
+				x0, x1 = min(x0, s.X[i]), max(x1, s.X[i])
+				y0, y1 = min(y0, s.Y[i]), max(y1, s.Y[i])
+			}
+		}
+	}
+	if x0 > x1 {
+		x0, x1 = 0, 1
+	}
+	if y0 > y1 {
+		y0, y1 = 0, 1
+	}
+	if x0 == x1 {
+		x0, x1 = x0-1, x1+1
+	}
+	if y0 == y1 {
+		y0, y1 = y0-1, y1+1
+	}
+	return x0, x1, y0, y1
+}
+
+// niceTicks returns about ticks round values spanning [low, high].
+func niceTicks(low, high float64) []float64 {
+	raw := (high - low) / ticks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{2, 5, 10} {
+		if step >= raw {
+			break
+		}
+		step = m * magnitude
+	}
+	var values []float64
+	for v := math.Floor(low/step) * step; v <= high+step/2; v += step {
+		values = append(values, math.Round(v/step)*step)
+		if len(values) > 2*ticks+2 {
+			break
+		}
+	}
+	return values
+}
+
+func label(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,178 @@
+package plot
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"unicode"
+)
+
+// raster draws on an image, text in a built-in bitmap font.
+type raster struct {
+	img *image.RGBA
+}
+
+// fontScale enlarges the 5x7 glyphs, which are drawn with a column and a row of space around them.
+const fontScale = 2
+
+func newRaster(width, height int) *raster {
+	return &raster{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (r *raster) rect(x, y, w, h float64, fill color.RGBA) {
+	for py := int(y); py < int(y+h); py++ {
+		for px := int(x); px < int(x+w); px++ {
+			r.img.SetRGBA(px, py, fill)
+		}
+	}
+}
+
+func (r *raster) polyline(xs, ys []float64, stroke color.RGBA, width float64) {
+	for i := 1; i < len(xs); i++ {
+		r.line(xs[i-1], ys[i-1], xs[i], ys[i], stroke, int(math.Max(width, 1)))
+	}
+	if len(xs) == 1 {
+		r.dot(int(xs[0]), int(ys[0]), stroke, int(math.Max(width, 1)))
+	}
+}
+
+// line draws a segment of the given width with Bresenham's algorithm.
+func (r *raster) line(x0f, y0f, x1f, y1f float64, c color.RGBA, width int) {
+	x0, y0, x1, y1 := int(math.Round(x0f)), int(math.Round(y0f)), int(math.Round(x1f)), int(math.Round(y1f))
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		r.dot(x0, y0, c, width)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func (r *raster) dot(x, y int, c color.RGBA, width int) {
+	for py := y - (width-1)/2; py <= y+width/2; py++ {
+		for px := x - (width-1)/2; px <= x+width/2; px++ {
+			r.img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+func (r *raster) text(x, y float64, text string, anchor anchor, fill color.RGBA) {
+	switch anchor {
+	case middle:
+		x -= r.textWidth(text) / 2
+	case end:
+		x -= r.textWidth(text)
+	}
+	left, top := int(math.Round(x)), int(math.Round(y))-7*fontScale/2
+	for _, ch := range text {
+		glyph, ok := font[unicode.ToUpper(ch)]
+		if !ok {
+			glyph = font['?']
+		}
+		for row, bits := range glyph {
+			for col := range 5 {
+				if bits&(1<<(4-col)) != 0 {
+					for py := range fontScale {
+						for px := range fontScale {
+							r.img.SetRGBA(left+col*fontScale+px, top+row*fontScale+py, fill)
+						}
+					}
+				}
+			}
+		}
+		left += 6 * fontScale
+	}
+}
+
+func (r *raster) textWidth(text string) float64 {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return float64((6*n - 1) * fontScale)
+}
+
+func (r *raster) encode(w io.Writer) error {
+	return png.Encode(w, r.img)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+// font holds 5x7 glyphs, one row of 5 bits per byte, the leftmost pixel in the highest bit.
+// Letters are drawn upper case.
+var font = map[rune][7]uint8{
+	' ': {},
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H': {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I': {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J': {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K': {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L': {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N': {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O': {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P': {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q': {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R': {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S': {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T': {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W': {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X': {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y': {0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100, 0b00100},
+	'Z': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	',': {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	':': {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'+': {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'_': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b11111},
+	'/': {0b00000, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b00000},
+	'%': {0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011},
+	'(': {0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010},
+	')': {0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000},
+	'?': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+}
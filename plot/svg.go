@@ -0,0 +1,59 @@
+package plot
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// svg builds an SVG document.
+type svg struct {
+	width, height int
+	b             strings.Builder
+}
+
+func newSVG(width, height int) *svg {
+	return &svg{width: width, height: height}
+}
+
+func (s *svg) rect(x, y, w, h float64, fill color.RGBA) {
+	fmt.Fprintf(&s.b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n", x, y, w, h, hex(fill))
+}
+
+func (s *svg) polyline(xs, ys []float64, stroke color.RGBA, width float64) {
+	if len(xs) == 0 {
+		return
+	}
+	s.b.WriteString("<polyline points=\"")
+	for i := range xs {
+		if i > 0 {
+			s.b.WriteByte(' ')
+		}
+		fmt.Fprintf(&s.b, "%.1f,%.1f", xs[i], ys[i])
+	}
+	fmt.Fprintf(&s.b, "\" fill=\"none\" stroke=\"%s\" stroke-width=\"%g\" stroke-linejoin=\"round\"/>\n", hex(stroke), width)
+}
+
+func (s *svg) text(x, y float64, text string, anchor anchor, fill color.RGBA) {
+	fmt.Fprintf(&s.b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"%s\" dominant-baseline=\"middle\" fill=\"%s\">",
+		x, y, [...]string{"start", "middle", "end"}[anchor], hex(fill))
+	xml.EscapeText(&s.b, []byte(text))
+	s.b.WriteString("</text>\n")
+}
+
+// textWidth estimates the width of text, SVG leaving measuring to the viewer.
+func (s *svg) textWidth(text string) float64 {
+	return 7 * float64(len([]rune(text)))
+}
+
+func (s *svg) encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"12\">\n",
+		s.width, s.height, s.width, s.height)
+	bw.WriteString(s.b.String())
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
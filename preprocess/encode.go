@@ -0,0 +1,94 @@
+package preprocess
+
+import (
+	"fmt"
+	"slices"
+)
+
+// OneHot replaces every given column, holding category codes, with one feature per category,
+// all zero but the one of the value. Categories are sorted, unseen values encode to all zeros.
+// Other columns are left as they are.
+type OneHot struct {
+	Columns    []int
+	Categories [][]float64 // per column
+}
+
+func (e *OneHot) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	e.Categories = make([][]float64, len(e.Columns))
+	for i, j := range e.Columns {
+		if j < 0 || j >= len(data[0]) {
+			return fmt.Errorf("preprocess: no column %d", j)
+		}
+		e.Categories[i] = categories(column(data, j))
+	}
+	return nil
+}
+
+func (e *OneHot) Transform(v []float64) []float64 {
+	result := make([]float64, 0, len(v))
+	for j, x := range v {
+		i := slices.Index(e.Columns, j)
+		if i < 0 {
+			result = append(result, x)
+			continue
+		}
+		n := len(result)
+		result = append(result, make([]float64, len(e.Categories[i]))...)
+		if k, ok := slices.BinarySearch(e.Categories[i], x); ok {
+			result[n+k] = 1
+		}
+	}
+	return result
+}
+
+// LabelEncoder replaces the values of the given columns with their index among the sorted
+// values of their column, e.g. class codes 3, 7 and 9 with 0, 1 and 2. Unseen values encode to -1.
+type LabelEncoder struct {
+	Columns []int
+	Classes [][]float64 // per column
+}
+
+func (e *LabelEncoder) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	e.Classes = make([][]float64, len(e.Columns))
+	for i, j := range e.Columns {
+		if j < 0 || j >= len(data[0]) {
+			return fmt.Errorf("preprocess: no column %d", j)
+		}
+		e.Classes[i] = categories(column(data, j))
+	}
+	return nil
+}
+
+func (e *LabelEncoder) Transform(v []float64) []float64 {
+	result := slices.Clone(v)
+	for i, j := range e.Columns {
+		k, ok := slices.BinarySearch(e.Classes[i], v[j])
+		if !ok {
+			k = -1
+		}
+		result[j] = float64(k)
+	}
+	return result
+}
+
+func (e *LabelEncoder) Inverse(v []float64) []float64 {
+	result := slices.Clone(v)
+	for i, j := range e.Columns {
+		if k := int(v[j]); k >= 0 && k < len(e.Classes[i]) {
+			result[j] = e.Classes[i][k]
+		}
+	}
+	return result
+}
+
+// categories returns the sorted distinct values.
+func categories(values []float64) []float64 {
+	slices.Sort(values)
+	return slices.Compact(values)
+}
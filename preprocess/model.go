@@ -0,0 +1,73 @@
+package preprocess
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"io"
+)
+
+// Model is a network along with the pipeline its inputs go through.
+// It predicts on raw inputs and is saved as one file.
+type Model struct {
+	Pipeline *Pipeline
+	Network  *feedforward.Network
+}
+
+// savedModel is the gob form of a Model.
+// The network is embedded in its JSON format, which records its activation.
+type savedModel struct {
+	Pipeline *Pipeline
+	Network  []byte
+}
+
+// Predict transforms input with the pipeline and predicts with the network.
+// It panics if input doesn't have the width the pipeline was fitted to.
+func (m *Model) Predict(input []float64) []float64 {
+	if len(input) != m.Pipeline.Inputs {
+		panic(fmt.Sprintf("input of %d values, pipeline was fitted to %d", len(input), m.Pipeline.Inputs))
+	}
+	return m.Network.Predict(m.Pipeline.Transform(input))
+}
+
+// Shapes returns the shapes of the network, the first one being the width of raw inputs.
+func (m *Model) Shapes() []int {
+	shapes := m.Network.Shapes()
+	shapes[0] = m.Pipeline.Inputs
+	return shapes
+}
+
+// ActivationName returns the activation name of the network.
+func (m *Model) ActivationName() string {
+	return m.Network.ActivationName()
+}
+
+// Metadata returns the metadata of the network.
+func (m *Model) Metadata() map[string]string {
+	return m.Network.Metadata()
+}
+
+func (m *Model) Save(w io.Writer) error {
+	var network bytes.Buffer
+	if err := m.Network.SaveJSON(&network); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(savedModel{
+		Pipeline: m.Pipeline,
+		Network:  network.Bytes(),
+	})
+}
+
+// LoadModel loads a model saved by Model.Save, applying the options to its network.
+func LoadModel(r io.Reader, opt ...feedforward.NetworkOpt) (*Model, error) {
+	var saved savedModel
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, err
+	}
+	nn, err := feedforward.Load(bytes.NewReader(saved.Network), opt...)
+	if err != nil {
+		return nil, err
+	}
+	return &Model{Pipeline: saved.Pipeline, Network: nn}, nil
+}
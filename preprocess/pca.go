@@ -0,0 +1,181 @@
+package preprocess
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// PCA projects vectors on the principal components of the data, the directions of largest variance,
+// keeping the first Components of them, all if zero. With Whiten set, projections are scaled
+// to unit variance, Epsilon (1e-5 if zero) being added to variances to keep small ones bounded.
+type PCA struct {
+	Components int
+	Whiten     bool
+	Epsilon    float64
+	Mean       []float64
+	Vectors    [][]float64 // principal components, by decreasing variance
+	Variances  []float64
+}
+
+func (p *PCA) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	n := len(data[0])
+	if p.Components < 0 || p.Components > n {
+		return fmt.Errorf("preprocess: %d components of %d features", p.Components, n)
+	}
+	if len(data) < 2 {
+		return fmt.Errorf("preprocess: PCA needs at least 2 rows")
+	}
+
+	p.Mean = make([]float64, n)
+	for _, row := range data {
+		for j, v := range row {
+			p.Mean[j] += v / float64(len(data))
+		}
+	}
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	centered := make([]float64, n)
+	for _, row := range data {
+		for j, v := range row {
+			centered[j] = v - p.Mean[j]
+		}
+		for i, ci := range centered {
+			for j, cj := range centered[i:] {
+				cov[i][i+j] += ci * cj
+			}
+		}
+	}
+	for i := range cov {
+		for j := i; j < n; j++ {
+			cov[i][j] /= float64(len(data) - 1)
+			cov[j][i] = cov[i][j]
+		}
+	}
+
+	values, vectors := jacobi(cov)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(values[b], values[a])
+	})
+	k := p.Components
+	if k == 0 {
+		k = n
+	}
+	p.Vectors = make([][]float64, k)
+	p.Variances = make([]float64, k)
+	for c, i := range order[:k] {
+		p.Vectors[c] = column(vectors, i)
+		p.Variances[c] = max(values[i], 0)
+	}
+	return nil
+}
+
+// scale returns the factor of component c.
+func (p *PCA) scale(c int) float64 {
+	if !p.Whiten {
+		return 1
+	}
+	eps := p.Epsilon
+	if eps == 0 {
+		eps = 1e-5
+	}
+	return 1 / math.Sqrt(p.Variances[c]+eps)
+}
+
+func (p *PCA) Transform(v []float64) []float64 {
+	result := make([]float64, len(p.Vectors))
+	for c, vector := range p.Vectors {
+		var sum float64
+		for j, x := range v {
+			sum += (x - p.Mean[j]) * vector[j]
+		}
+		result[c] = sum * p.scale(c)
+	}
+	return result
+}
+
+// Inverse maps projections back to the original space, exactly if all components are kept.
+func (p *PCA) Inverse(v []float64) []float64 {
+	result := slices.Clone(p.Mean)
+	for c, vector := range p.Vectors {
+		y := v[c] / p.scale(c)
+		for j, x := range vector {
+			result[j] += y * x
+		}
+	}
+	return result
+}
+
+// jacobi returns the eigenvalues of the symmetric matrix a and its eigenvectors as the columns of a matrix,
+// by cyclic Jacobi rotations. The matrix is overwritten.
+func jacobi(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	var norm float64
+	for i := range a {
+		for _, x := range a[i] {
+			norm += x * x
+		}
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		var off float64
+		for i := range a {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off <= 1e-24*norm {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := range n {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := range n {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := range n {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return values, v
+}
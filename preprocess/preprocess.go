@@ -0,0 +1,135 @@
+// Package preprocess fits transforms to training data and applies them to inputs,
+// so that predictions see inputs transformed exactly as during training.
+//
+// Transformers are composed into a Pipeline, which is saved along with its network as a Model.
+package preprocess
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/dataset"
+)
+
+// Transformer learns a transform of vectors from data.
+type Transformer interface {
+	// Fit learns the transform from rows of data.
+	Fit(data [][]float64) error
+	// Transform returns the transformed copy of v.
+	Transform(v []float64) []float64
+}
+
+// Inverter is a transformer that can undo its transform, e.g. to turn scaled outputs back.
+type Inverter interface {
+	Transformer
+	Inverse(v []float64) []float64
+}
+
+func init() {
+	// pipelines are saved with gob, which must know the transformers behind the interface
+	gob.Register(&MinMax{})
+	gob.Register(&Standard{})
+	gob.Register(&Robust{})
+	gob.Register(&OneHot{})
+	gob.Register(&LabelEncoder{})
+	gob.Register(&PCA{})
+}
+
+// Pipeline applies transformers one after the other.
+type Pipeline struct {
+	Steps []Transformer
+	// Inputs is the width of the vectors the pipeline was fitted to.
+	Inputs int
+}
+
+// NewPipeline returns a pipeline of the given steps, to be fitted.
+func NewPipeline(steps ...Transformer) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+// Fit fits every step to the data as transformed by the steps before it.
+func (p *Pipeline) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	p.Inputs = len(data[0])
+	for i, step := range p.Steps {
+		if err := step.Fit(data); err != nil {
+			return fmt.Errorf("step %d (%T): %w", i, step, err)
+		}
+		if i < len(p.Steps)-1 {
+			data = transformAll(step, data)
+		}
+	}
+	return nil
+}
+
+// FitTransform fits the pipeline to data and returns the transformed data.
+func (p *Pipeline) FitTransform(data [][]float64) ([][]float64, error) {
+	if err := p.Fit(data); err != nil {
+		return nil, err
+	}
+	return p.TransformAll(data), nil
+}
+
+// Transform returns v transformed by every step.
+func (p *Pipeline) Transform(v []float64) []float64 {
+	for _, step := range p.Steps {
+		v = step.Transform(v)
+	}
+	return v
+}
+
+// TransformAll transforms every row of data.
+func (p *Pipeline) TransformAll(data [][]float64) [][]float64 {
+	return transformAll(p, data)
+}
+
+// Inverse undoes the transforms of the pipeline, failing if a step can't be undone.
+func (p *Pipeline) Inverse(v []float64) ([]float64, error) {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		inv, ok := p.Steps[i].(Inverter)
+		if !ok {
+			return nil, fmt.Errorf("step %d (%T) can't be undone", i, p.Steps[i])
+		}
+		v = inv.Inverse(v)
+	}
+	return v, nil
+}
+
+// Dataset returns ds with its inputs transformed by the pipeline as examples are read.
+func (p *Pipeline) Dataset(ds dataset.Dataset) dataset.Dataset {
+	return dataset.Map(ds, func(ex dataset.Example) dataset.Example {
+		return dataset.Example{Input: p.Transform(ex.Input), Target: ex.Target}
+	})
+}
+
+func transformAll(t interface{ Transform([]float64) []float64 }, data [][]float64) [][]float64 {
+	result := make([][]float64, len(data))
+	for i, row := range data {
+		result[i] = t.Transform(row)
+	}
+	return result
+}
+
+// check reports data without rows or with rows of different widths.
+func check(data [][]float64) error {
+	if len(data) == 0 {
+		return errors.New("preprocess: no data")
+	}
+	for i, row := range data {
+		if len(row) != len(data[0]) {
+			return fmt.Errorf("preprocess: row %d has %d values, row 0 has %d", i, len(row), len(data[0]))
+		}
+	}
+	return nil
+}
+
+// column returns column j of data.
+func column(data [][]float64, j int) []float64 {
+	c := make([]float64, len(data))
+	for i, row := range data {
+		c[i] = row[j]
+	}
+	return c
+}
@@ -0,0 +1,213 @@
+package preprocess
+
+import (
+	"bytes"
+	"github.com/lnashier/gonet/feedforward"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func near(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+// data has a constant third column.
+var data = [][]float64{
+	{1, 10, 5},
+	{2, 20, 5},
+	{3, 30, 5},
+	{4, 40, 5},
+	{10, 0, 5},
+}
+
+func TestScalers(t *testing.T) {
+	tests := map[string]struct {
+		scaler Inverter
+		// transform of data[1]
+		want []float64
+	}{
+		"minmax":       {&MinMax{}, []float64{1.0 / 9, 0.5, 0}},
+		"minmax range": {&MinMax{Low: -1, High: 1}, []float64{-1 + 2.0/9, 0, -1}},
+		// mean 4 20 5, std sqrt(10) sqrt(200) 0
+		"standard": {&Standard{}, []float64{-2 / math.Sqrt(10), 0, 0}},
+		// median 3 20 5, IQR 2 20 0
+		"robust": {&Robust{}, []float64{-0.5, 0, 0}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.scaler.Fit(data); err != nil {
+				t.Fatal(err)
+			}
+			near(t, "transform", test.scaler.Transform(data[1]), test.want)
+			for _, row := range data {
+				near(t, "inverse", test.scaler.Inverse(test.scaler.Transform(row)), row)
+			}
+		})
+	}
+}
+
+func TestMinMaxEqualBounds(t *testing.T) {
+	s := &MinMax{Low: 0.5, High: 0.5}
+	if err := s.Fit(data); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "transform", s.Transform(data[3]), []float64{0.5, 0.5, 0.5})
+	// every value was scaled to the same one, the minimum stands for them
+	near(t, "inverse", s.Inverse([]float64{0.5, 0.5, 0.5}), []float64{1, 0, 5})
+}
+
+func TestEncoders(t *testing.T) {
+	codes := [][]float64{{7, 0.5}, {3, 1.5}, {9, 2.5}, {3, 3.5}}
+
+	oneHot := &OneHot{Columns: []int{0}}
+	if err := oneHot.Fit(codes); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "one-hot", oneHot.Transform(codes[0]), []float64{0, 1, 0, 0.5})
+	near(t, "one-hot unseen", oneHot.Transform([]float64{5, 1}), []float64{0, 0, 0, 1})
+
+	labels := &LabelEncoder{Columns: []int{0}}
+	if err := labels.Fit(codes); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "labels", labels.Transform(codes[2]), []float64{2, 2.5})
+	near(t, "labels unseen", labels.Transform([]float64{5, 1}), []float64{-1, 1})
+	for _, row := range codes {
+		near(t, "labels inverse", labels.Inverse(labels.Transform(row)), row)
+	}
+
+	if err := (&OneHot{Columns: []int{2}}).Fit(codes); err == nil {
+		t.Error("fitted a missing column")
+	}
+}
+
+func TestJacobi(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 0.5},
+		{1, 3, 1},
+		{0.5, 1, 2},
+	}
+	values, vectors := jacobi([][]float64{slices.Clone(a[0]), slices.Clone(a[1]), slices.Clone(a[2])})
+
+	// a v = λ v for orthonormal eigenvectors, the trace being the sum of eigenvalues
+	trace := 0.0
+	for i := range values {
+		v := column(vectors, i)
+		av := make([]float64, 3)
+		lv := make([]float64, 3)
+		for r := range a {
+			for c := range a[r] {
+				av[r] += a[r][c] * v[c]
+			}
+			lv[r] = values[i] * v[r]
+		}
+		near(t, "eigenvector", av, lv)
+		for j := range values {
+			u := column(vectors, j)
+			dot := u[0]*v[0] + u[1]*v[1] + u[2]*v[2]
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			near(t, "orthonormal", []float64{dot}, []float64{want})
+		}
+		trace += values[i]
+	}
+	near(t, "trace", []float64{trace}, []float64{9})
+}
+
+func TestPCA(t *testing.T) {
+	// points along the diagonals, with variances 8/3 along (1, 1) and 2/3 along (-1, 1)
+	s := 1 / math.Sqrt2
+	points := [][]float64{{2 * s, 2 * s}, {-2 * s, -2 * s}, {-s, s}, {s, -s}}
+	for i := range points {
+		points[i][0] += 3
+		points[i][1] -= 1
+	}
+
+	p := &PCA{}
+	if err := p.Fit(points); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "mean", p.Mean, []float64{3, -1})
+	near(t, "variances", p.Variances, []float64{8.0 / 3, 2.0 / 3})
+	// components are defined up to their sign
+	for c, want := range [][]float64{{s, s}, {-s, s}} {
+		dot := p.Vectors[c][0]*want[0] + p.Vectors[c][1]*want[1]
+		near(t, "component", []float64{math.Abs(dot)}, []float64{1})
+	}
+	for _, row := range points {
+		near(t, "inverse", p.Inverse(p.Transform(row)), row)
+	}
+
+	// whitened projections have unit variance
+	w := &PCA{Components: 1, Whiten: true, Epsilon: 1e-12}
+	if err := w.Fit(points); err != nil {
+		t.Fatal(err)
+	}
+	var variance float64
+	for _, row := range points {
+		y := w.Transform(row)
+		if len(y) != 1 {
+			t.Fatalf("got %d components, want 1", len(y))
+		}
+		variance += y[0] * y[0] / float64(len(points)-1)
+	}
+	near(t, "whitened variance", []float64{variance}, []float64{1})
+
+	if err := (&PCA{Components: 3}).Fit(points); err == nil {
+		t.Error("fitted more components than features")
+	}
+}
+
+func TestModelSaveLoad(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	raw := make([][]float64, 20)
+	for i := range raw {
+		raw[i] = []float64{float64(r.Intn(3)), r.Float64() * 10, r.NormFloat64()}
+	}
+	pipeline := NewPipeline(&OneHot{Columns: []int{0}}, &Standard{}, &PCA{Components: 3, Whiten: true})
+	inputs, err := pipeline.FitTransform(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pipeline.Inputs != 3 || len(inputs[0]) != 3 {
+		t.Fatalf("pipeline of %d inputs transforms to %d", pipeline.Inputs, len(inputs[0]))
+	}
+
+	rand.Seed(1)
+	m := &Model{
+		Pipeline: pipeline,
+		Network:  feedforward.New(feedforward.Shapes([]int{3, 4, 1}), feedforward.NamedActivation("tanh")),
+	}
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Shapes(), []int{3, 4, 1}) || loaded.ActivationName() != "tanh" {
+		t.Fatalf("got %v %s", loaded.Shapes(), loaded.ActivationName())
+	}
+	for _, row := range raw {
+		if got, want := loaded.Predict(row), m.Predict(row); !slices.Equal(got, want) {
+			t.Fatalf("loaded model predicts %v, want %v", got, want)
+		}
+	}
+
+	if _, err := m.Pipeline.Inverse(inputs[0]); err == nil {
+		t.Error("inverted a one-hot encoding")
+	}
+}
@@ -0,0 +1,159 @@
+package preprocess
+
+import (
+	"math"
+	"slices"
+)
+
+// MinMax scales every feature linearly from its range in the data to [Low, High], [0, 1] if both are zero.
+type MinMax struct {
+	Low, High float64
+	Min, Max  []float64
+}
+
+func (s *MinMax) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	s.Min = slices.Clone(data[0])
+	s.Max = slices.Clone(data[0])
+	for _, row := range data[1:] {
+		for j, v := range row {
+			s.Min[j] = min(s.Min[j], v)
+			s.Max[j] = max(s.Max[j], v)
+		}
+	}
+	return nil
+}
+
+func (s *MinMax) bounds() (float64, float64) {
+	if s.Low == 0 && s.High == 0 {
+		return 0, 1
+	}
+	return s.Low, s.High
+}
+
+func (s *MinMax) Transform(v []float64) []float64 {
+	low, high := s.bounds()
+	result := make([]float64, len(v))
+	for j, x := range v {
+		if span := s.Max[j] - s.Min[j]; span != 0 {
+			result[j] = low + (x-s.Min[j])/span*(high-low)
+		} else {
+			result[j] = low
+		}
+	}
+	return result
+}
+
+func (s *MinMax) Inverse(v []float64) []float64 {
+	low, high := s.bounds()
+	result := make([]float64, len(v))
+	for j, y := range v {
+		if high == low {
+			// every value was scaled to low, like constant features
+			result[j] = s.Min[j]
+			continue
+		}
+		result[j] = s.Min[j] + (y-low)/(high-low)*(s.Max[j]-s.Min[j])
+	}
+	return result
+}
+
+// Standard scales every feature to zero mean and unit variance.
+type Standard struct {
+	Mean, Std []float64
+}
+
+func (s *Standard) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	n := float64(len(data))
+	s.Mean = make([]float64, len(data[0]))
+	s.Std = make([]float64, len(data[0]))
+	for _, row := range data {
+		for j, v := range row {
+			s.Mean[j] += v / n
+		}
+	}
+	for _, row := range data {
+		for j, v := range row {
+			s.Std[j] += (v - s.Mean[j]) * (v - s.Mean[j]) / n
+		}
+	}
+	for j, v := range s.Std {
+		s.Std[j] = math.Sqrt(v)
+	}
+	return nil
+}
+
+func (s *Standard) Transform(v []float64) []float64 {
+	return affine(v, s.Mean, s.Std)
+}
+
+func (s *Standard) Inverse(v []float64) []float64 {
+	return inverseAffine(v, s.Mean, s.Std)
+}
+
+// Robust scales every feature by removing its median and dividing by its interquartile range,
+// which outliers barely affect.
+type Robust struct {
+	Median, IQR []float64
+}
+
+func (s *Robust) Fit(data [][]float64) error {
+	if err := check(data); err != nil {
+		return err
+	}
+	s.Median = make([]float64, len(data[0]))
+	s.IQR = make([]float64, len(data[0]))
+	for j := range s.Median {
+		c := column(data, j)
+		slices.Sort(c)
+		s.Median[j] = quantile(c, 0.5)
+		s.IQR[j] = quantile(c, 0.75) - quantile(c, 0.25)
+	}
+	return nil
+}
+
+func (s *Robust) Transform(v []float64) []float64 {
+	return affine(v, s.Median, s.IQR)
+}
+
+func (s *Robust) Inverse(v []float64) []float64 {
+	return inverseAffine(v, s.Median, s.IQR)
+}
+
+// quantile interpolates linearly between the sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i == len(sorted)-1 {
+		return sorted[i]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// affine returns (v - center) / scale, leaving constant features centered but unscaled.
+func affine(v, center, scale []float64) []float64 {
+	result := make([]float64, len(v))
+	for j, x := range v {
+		result[j] = x - center[j]
+		if scale[j] != 0 {
+			result[j] /= scale[j]
+		}
+	}
+	return result
+}
+
+func inverseAffine(v, center, scale []float64) []float64 {
+	result := make([]float64, len(v))
+	for j, y := range v {
+		if scale[j] != 0 {
+			y *= scale[j]
+		}
+		result[j] = y + center[j]
+	}
+	return result
+}
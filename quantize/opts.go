@@ -0,0 +1,33 @@
+package quantize
+
+type Opt func(*opts)
+
+type opts struct {
+	scaling     Scaling
+	calibration [][]float64
+}
+
+var defaultOpts = opts{
+	scaling: PerChannel,
+}
+
+func (s *opts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Scales sets whether weights are scaled per layer or per node. Defaults to PerChannel.
+func Scales(v Scaling) Opt {
+	return func(s *opts) {
+		s.scaling = v
+	}
+}
+
+// Calibration sets representative inputs used to fix the quantization range of activations.
+// Without calibration, activations are quantized per prediction from their absolute maximum.
+func Calibration(v [][]float64) Opt {
+	return func(s *opts) {
+		s.calibration = v
+	}
+}
@@ -0,0 +1,247 @@
+// Package quantize converts trained feedforward networks to int8 weights
+// for inference on memory-constrained targets.
+package quantize
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"math"
+	"slices"
+)
+
+// Model is a quantized feedforward network.
+// Weights are symmetric int8 values, w ≈ q * scale, and dot products are computed on int32 accumulators.
+type Model struct {
+	Shapes []int
+	Layers []*Layer
+	af     func(float64) float64
+}
+
+// Layer holds the quantized weights of a dense layer as a row-major out x in matrix.
+type Layer struct {
+	Weights []int8
+	// Scales holds one scale for the whole layer or one per node (output channel).
+	Scales []float64
+	Biases []float64
+	// InputScale is the calibrated scale of the layer inputs.
+	// Zero means inputs are quantized per prediction from their absolute maximum.
+	InputScale float64
+}
+
+// Scaling selects how many scales a layer's weights share.
+type Scaling int
+
+const (
+	PerLayer Scaling = iota
+	PerChannel
+)
+
+// Quantize converts weights of the network to int8.
+func Quantize[T fns.Float](nn *feedforward.Net[T], opt ...Opt) (*Model, error) {
+	opts := defaultOpts
+	opts.apply(opt)
+
+	if nn.Activation() == nil {
+		return nil, errors.New("network has no activation function")
+	}
+
+	m := &Model{
+		Shapes: nn.Shapes(),
+		af:     nn.Activation(),
+	}
+
+	weights := make([][]float64, len(m.Shapes)-1)
+	for l := range weights {
+		in, out := m.Shapes[l], m.Shapes[l+1]
+		w := fns.Convert[float64](nn.Weights(l))
+		weights[l] = w
+
+		layer := &Layer{
+			Weights: make([]int8, len(w)),
+			Biases:  fns.Convert[float64](nn.Biases(l)),
+		}
+
+		switch opts.scaling {
+		case PerLayer:
+			scale := scaleOf(w)
+			layer.Scales = []float64{scale}
+			quantize(layer.Weights, w, scale)
+		case PerChannel:
+			layer.Scales = make([]float64, out)
+			for j := range out {
+				row := w[j*in : (j+1)*in]
+				layer.Scales[j] = scaleOf(row)
+				quantize(layer.Weights[j*in:(j+1)*in], row, layer.Scales[j])
+			}
+		default:
+			return nil, errors.New("unknown scaling")
+		}
+
+		m.Layers = append(m.Layers, layer)
+	}
+
+	if len(opts.calibration) > 0 {
+		m.calibrate(weights, opts.calibration)
+	}
+
+	return m, nil
+}
+
+// calibrate fixes the input scale of every layer from the ranges the float network
+// produces over the calibration inputs.
+func (m *Model) calibrate(weights [][]float64, inputs [][]float64) {
+	maxAbs := make([]float64, len(m.Layers))
+	for _, input := range inputs {
+		activation := input
+		for l, layer := range m.Layers {
+			for _, v := range activation {
+				maxAbs[l] = max(maxAbs[l], math.Abs(v))
+			}
+			out := make([]float64, len(layer.Biases))
+			for j := range out {
+				sum := layer.Biases[j]
+				for i, w := range weights[l][j*len(activation) : (j+1)*len(activation)] {
+					sum += w * activation[i]
+				}
+				out[j] = m.af(sum)
+			}
+			activation = out
+		}
+	}
+	for l, layer := range m.Layers {
+		layer.InputScale = 1
+		if maxAbs[l] > 0 {
+			layer.InputScale = maxAbs[l] / math.MaxInt8
+		}
+	}
+}
+
+// Predict runs the quantized network.
+// Inputs of every layer are quantized to int8, multiplied with the weights in integer arithmetic,
+// then rescaled to float for the bias and the activation function.
+// It panics if the input does not match the input layer.
+func (m *Model) Predict(input []float64) []float64 {
+	q := make([]int8, 0, slices.Max(m.Shapes))
+	activation := input
+	for _, layer := range m.Layers {
+		activation = layer.forward(activation, q, m.af)
+	}
+	return activation
+}
+
+func (l *Layer) forward(x []float64, q []int8, af func(float64) float64) []float64 {
+	in := len(x)
+	out := len(l.Biases)
+	if in*out != len(l.Weights) {
+		panic(fmt.Sprintf("quantize: input of %d values, layer has %d weights for %d nodes", in, len(l.Weights), out))
+	}
+
+	inScale := l.InputScale
+	if inScale == 0 {
+		inScale = scaleOf(x)
+	}
+	q = q[:0]
+	for _, v := range x {
+		q = append(q, quantizeValue(v, inScale))
+	}
+
+	activation := make([]float64, out)
+	for j := range out {
+		var acc int32
+		for i, w := range l.Weights[j*in : (j+1)*in] {
+			acc += int32(w) * int32(q[i])
+		}
+		scale := l.Scales[0]
+		if len(l.Scales) > 1 {
+			scale = l.Scales[j]
+		}
+		activation[j] = af(float64(acc)*inScale*scale + l.Biases[j])
+	}
+	return activation
+}
+
+// Save writes the quantized model.
+func (m *Model) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(m)
+}
+
+// Load reads a quantized model written by Save.
+// Activation functions are not saved, af must match the one of the original network.
+func Load(r io.Reader, af func(float64) float64) (*Model, error) {
+	if af == nil {
+		return nil, errors.New("missing activation function")
+	}
+	m := &Model{}
+	if err := gob.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	m.af = af
+	return m, nil
+}
+
+// validate checks that the layers match the shapes of the model.
+func (m *Model) validate() error {
+	if len(m.Shapes) < 2 {
+		return fmt.Errorf("invalid shapes %v", m.Shapes)
+	}
+	for _, s := range m.Shapes {
+		if s <= 0 {
+			return fmt.Errorf("invalid shapes %v", m.Shapes)
+		}
+	}
+	if len(m.Layers) != len(m.Shapes)-1 {
+		return fmt.Errorf("%d layers for shapes %v", len(m.Layers), m.Shapes)
+	}
+	for l, layer := range m.Layers {
+		in, out := m.Shapes[l], m.Shapes[l+1]
+		switch {
+		case layer == nil:
+			return fmt.Errorf("layer %d: missing", l)
+		case len(layer.Weights) != in*out:
+			return fmt.Errorf("layer %d: %d weights, want %d", l, len(layer.Weights), in*out)
+		case len(layer.Biases) != out:
+			return fmt.Errorf("layer %d: %d biases, want %d", l, len(layer.Biases), out)
+		case len(layer.Scales) != 1 && len(layer.Scales) != out:
+			return fmt.Errorf("layer %d: %d scales, want 1 or %d", l, len(layer.Scales), out)
+		}
+	}
+	return nil
+}
+
+// Size returns the number of bytes taken by the weights, biases and scales of the model.
+func (m *Model) Size() int {
+	size := 0
+	for _, l := range m.Layers {
+		size += len(l.Weights) + 8*(len(l.Scales)+len(l.Biases)+1)
+	}
+	return size
+}
+
+// scaleOf returns the symmetric scale mapping the absolute maximum of values to 127.
+func scaleOf(values []float64) float64 {
+	maxAbs := 0.0
+	for _, v := range values {
+		maxAbs = max(maxAbs, math.Abs(v))
+	}
+	if maxAbs == 0 {
+		return 1
+	}
+	return maxAbs / math.MaxInt8
+}
+
+func quantize(dst []int8, src []float64, scale float64) {
+	for i, v := range src {
+		dst[i] = quantizeValue(v, scale)
+	}
+}
+
+func quantizeValue(v, scale float64) int8 {
+	return int8(max(-math.MaxInt8, min(math.MaxInt8, math.Round(v/scale))))
+}
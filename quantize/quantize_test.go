@@ -0,0 +1,78 @@
+package quantize
+
+import (
+	"bytes"
+	"github.com/lnashier/gonet/feedforward"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func testModel(t *testing.T) *Model {
+	t.Helper()
+	rand.Seed(1)
+	nn := feedforward.New(feedforward.Shapes([]int{3, 4, 2}), feedforward.NamedActivation("tanh"))
+	m, err := Quantize(nn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := testModel(t)
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf, math.Tanh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []float64{0.5, -1, 0.25}
+	if got, want := loaded.Predict(input), m.Predict(input); !slices.Equal(got, want) {
+		t.Fatalf("loaded model predicts %v, want %v", got, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]func(m *Model){
+		"shapes": func(m *Model) {
+			m.Shapes = []int{3}
+		},
+		"layers": func(m *Model) {
+			m.Layers = m.Layers[:1]
+		},
+		"weights": func(m *Model) {
+			m.Layers[0].Weights = m.Layers[0].Weights[:5]
+		},
+		"biases": func(m *Model) {
+			m.Layers[1].Biases = append(m.Layers[1].Biases, 0)
+		},
+		"scales": func(m *Model) {
+			m.Layers[0].Scales = m.Layers[0].Scales[:2]
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := testModel(t)
+			mutate(m)
+			var buf bytes.Buffer
+			if err := m.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(&buf, math.Tanh); err == nil {
+				t.Fatal("loaded an invalid model")
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := testModel(t).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(&buf, nil); err == nil {
+		t.Fatal("loaded a model without activation")
+	}
+}
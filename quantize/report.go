@@ -0,0 +1,59 @@
+package quantize
+
+import (
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"math"
+	"unsafe"
+)
+
+// Report compares a quantized model with the float network it was built from.
+type Report struct {
+	// Float and Quantized are the metric values of both models, Drop is Float - Quantized.
+	Float     float64
+	Quantized float64
+	Drop      float64
+	// Agreement is the fraction of inputs for which both models predict the same class (argmax).
+	Agreement float64
+	// MaxError is the largest absolute difference between outputs of both models.
+	MaxError float64
+	// FloatBytes and QuantizedBytes are the sizes of the model parameters.
+	FloatBytes     int
+	QuantizedBytes int
+}
+
+// Compare evaluates both models over inputs and targets with metric, e.g. an accuracy.
+func Compare[T fns.Float](m *Model, nn *feedforward.Net[T], inputs, targets [][]T, metric func(predictions, targets [][]float64) float64) Report {
+	floatPredictions := make([][]float64, len(inputs))
+	quantizedPredictions := make([][]float64, len(inputs))
+
+	r := Report{}
+	agreed := 0
+	for i, input := range inputs {
+		floatPredictions[i] = fns.Convert[float64](nn.Predict(input))
+		quantizedPredictions[i] = m.Predict(fns.Convert[float64](input))
+		if fns.Argmax(floatPredictions[i]) == fns.Argmax(quantizedPredictions[i]) {
+			agreed++
+		}
+		for j, v := range floatPredictions[i] {
+			r.MaxError = max(r.MaxError, math.Abs(v-quantizedPredictions[i][j]))
+		}
+	}
+
+	floatTargets := fns.ConvertMat[float64](targets)
+	r.Float = metric(floatPredictions, floatTargets)
+	r.Quantized = metric(quantizedPredictions, floatTargets)
+	r.Drop = r.Float - r.Quantized
+	if len(inputs) > 0 {
+		r.Agreement = float64(agreed) / float64(len(inputs))
+	}
+
+	var zero T
+	shapes := nn.Shapes()
+	for l := range len(shapes) - 1 {
+		r.FloatBytes += (shapes[l] + 1) * shapes[l+1] * int(unsafe.Sizeof(zero))
+	}
+	r.QuantizedBytes = m.Size()
+
+	return r
+}
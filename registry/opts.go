@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"github.com/lnashier/gonet/feedforward"
+	"time"
+)
+
+type Opt func(*registryOpts)
+
+type registryOpts struct {
+	keep        int
+	interval    time.Duration
+	pattern     string
+	networkOpts []feedforward.NetworkOpt
+	onChange    func(*Version)
+	onError     func(error)
+}
+
+var defaultRegistryOpts = registryOpts{
+	keep:     3,
+	interval: 5 * time.Second,
+	pattern:  "*",
+	onChange: func(*Version) {},
+	onError:  func(error) {},
+}
+
+func (s *registryOpts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Keep sets how many versions stay loaded for rollbacks, the pinned one aside. Defaults to 3.
+func Keep(v int) Opt {
+	return func(s *registryOpts) {
+		s.keep = v
+	}
+}
+
+// Interval sets how often Watch scans the directory. Defaults to 5 seconds.
+func Interval(v time.Duration) Opt {
+	return func(s *registryOpts) {
+		s.interval = v
+	}
+}
+
+// Pattern restricts the files loaded to those matching the filepath.Match pattern, e.g. "*.json".
+func Pattern(v string) Opt {
+	return func(s *registryOpts) {
+		s.pattern = v
+	}
+}
+
+// NetworkOpts sets the options of every loaded network, e.g. the activation of gob files.
+func NetworkOpts(v ...feedforward.NetworkOpt) Opt {
+	return func(s *registryOpts) {
+		s.networkOpts = v
+	}
+}
+
+// OnChange sets a function called with every version becoming current.
+func OnChange(v func(*Version)) Opt {
+	return func(s *registryOpts) {
+		s.onChange = v
+	}
+}
+
+// OnError sets a function called with the errors of background scans and of files failing to load.
+func OnError(v func(error)) Opt {
+	return func(s *registryOpts) {
+		s.onError = v
+	}
+}
@@ -0,0 +1,295 @@
+// Package registry serves the latest of the networks saved in a directory,
+// reloading them as they change so that retrained models are swapped in without restarts.
+//
+// Every file of the directory is a version of the model, the most recently modified
+// one being current unless a version is pinned. Files are best written elsewhere and
+// renamed into the directory, those that fail to load are retried once modified.
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Version is a loaded network. It is never modified and safe for concurrent use.
+type Version struct {
+	// ID is the name of the file in the directory.
+	ID      string
+	ModTime time.Time
+	Loaded  time.Time
+	Network *feedforward.Network
+}
+
+// Registry holds the last loaded versions of a model. Its methods are safe for concurrent use,
+// predictions always go to a single, fully loaded version.
+//
+// Every method resolves the current version anew, so a version may be swapped in between two calls.
+// Callers combining them, e.g. checking the input width before predicting, must resolve
+// the version once with Current and use its Network throughout.
+type Registry struct {
+	dir  string
+	opts registryOpts
+
+	mu       sync.Mutex // serializes scans, pins and rollbacks
+	versions []*Version // oldest first
+	pinned   string
+	seen     map[string]time.Time // modification times of the files scanned
+
+	current atomic.Pointer[Version]
+}
+
+// New loads the models in dir, failing if none loads.
+func New(dir string, opt ...Opt) (*Registry, error) {
+	opts := defaultRegistryOpts
+	opts.apply(opt)
+
+	r := &Registry{
+		dir:  dir,
+		opts: opts,
+		seen: map[string]time.Time{},
+	}
+	if err := r.Scan(); err != nil {
+		return nil, err
+	}
+	if r.current.Load() == nil {
+		return nil, fmt.Errorf("%s: no models", dir)
+	}
+	return r, nil
+}
+
+// Watch scans the directory at the configured interval until ctx is done.
+func (r *Registry) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Scan(); err != nil {
+				r.opts.onError(err)
+			}
+		}
+	}
+}
+
+// Scan loads the new and modified files of the directory, newest first and
+// no more than are kept. Errors loading single files are reported to the error handler, not returned.
+func (r *Registry) Scan() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed []*Version
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if ok, _ := filepath.Match(r.opts.pattern, e.Name()); !ok {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue // removed since read
+		}
+		if t, ok := r.seen[e.Name()]; ok && t.Equal(fi.ModTime()) {
+			continue
+		}
+		r.seen[e.Name()] = fi.ModTime()
+		changed = append(changed, &Version{ID: e.Name(), ModTime: fi.ModTime()})
+	}
+	slices.SortFunc(changed, compare)
+
+	loaded := 0
+	for i := len(changed) - 1; i >= 0 && loaded < max(r.opts.keep, 1); i-- {
+		v := changed[i]
+		nn, err := r.load(v.ID)
+		if err != nil {
+			// retried once modified
+			r.opts.onError(fmt.Errorf("%s: %w", v.ID, err))
+			continue
+		}
+		v.Loaded = time.Now()
+		v.Network = nn
+		r.add(v)
+		loaded++
+	}
+	r.evict()
+	r.publish()
+	return nil
+}
+
+func (r *Registry) load(name string) (*feedforward.Network, error) {
+	f, err := os.Open(filepath.Join(r.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	nn, err := feedforward.Load(f, r.opts.networkOpts...)
+	if err != nil {
+		return nil, err
+	}
+	// gob models don't record their activation, serving them would panic
+	if nn.Activation() == nil {
+		return nil, fmt.Errorf("model doesn't record its activation, set one with NetworkOpts")
+	}
+	return nn, nil
+}
+
+// add inserts v in modification order, replacing the version of the same file.
+func (r *Registry) add(v *Version) {
+	r.versions = slices.DeleteFunc(r.versions, func(o *Version) bool {
+		return o.ID == v.ID
+	})
+	i, _ := slices.BinarySearchFunc(r.versions, v, compare)
+	r.versions = slices.Insert(r.versions, i, v)
+}
+
+// compare orders versions by modification time, then name.
+func compare(a, b *Version) int {
+	if c := a.ModTime.Compare(b.ModTime); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// evict drops the oldest versions beyond those kept, the pinned one aside.
+func (r *Registry) evict() {
+	n := len(r.versions)
+	if r.find(r.pinned) != nil {
+		n--
+	}
+	for i := 0; n > max(r.opts.keep, 1); {
+		if r.versions[i].ID == r.pinned {
+			i++
+			continue
+		}
+		r.versions = slices.Delete(r.versions, i, i+1)
+		n--
+	}
+}
+
+// publish makes the pinned version, or else the newest, current.
+func (r *Registry) publish() {
+	if len(r.versions) == 0 {
+		return
+	}
+	v := r.versions[len(r.versions)-1]
+	if p := r.find(r.pinned); p != nil {
+		v = p
+	}
+	if old := r.current.Swap(v); old != v {
+		r.opts.onChange(v)
+	}
+}
+
+func (r *Registry) find(id string) *Version {
+	for _, v := range r.versions {
+		if v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// Current returns the version predictions go to, unchanged by later swaps.
+func (r *Registry) Current() *Version {
+	return r.current.Load()
+}
+
+// Versions returns the loaded versions, oldest first.
+func (r *Registry) Versions() []*Version {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.versions)
+}
+
+// Pin makes the given version current until unpinned, whatever versions are loaded later.
+func (r *Registry) Pin(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.find(id) == nil {
+		return fmt.Errorf("version not loaded: %s", id)
+	}
+	r.pinned = id
+	r.publish()
+	return nil
+}
+
+// Unpin makes the newest version current again.
+func (r *Registry) Unpin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pinned = ""
+	r.evict()
+	r.publish()
+}
+
+// Pinned returns the pinned version, empty if none.
+func (r *Registry) Pinned() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pinned
+}
+
+// Rollback pins the version loaded before the current one.
+func (r *Registry) Rollback() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur := r.current.Load()
+	i := slices.Index(r.versions, cur)
+	if i <= 0 {
+		return errors.New("no version to roll back to")
+	}
+	r.pinned = r.versions[i-1].ID
+	r.publish()
+	return nil
+}
+
+// Predict predicts with the current version.
+func (r *Registry) Predict(input []float64) []float64 {
+	return r.current.Load().Network.Predict(input)
+}
+
+// PredictInto predicts with the current version into dst, see feedforward.Net.PredictInto.
+func (r *Registry) PredictInto(dst, input []float64) []float64 {
+	return r.current.Load().Network.PredictInto(dst, input)
+}
+
+// PredictBatch predicts a batch with the current version, see feedforward.Net.PredictBatch.
+func (r *Registry) PredictBatch(inputs [][]float64) [][]float64 {
+	return r.current.Load().Network.PredictBatch(inputs)
+}
+
+// Shapes returns the shapes of the current version.
+func (r *Registry) Shapes() []int {
+	return r.current.Load().Network.Shapes()
+}
+
+// ActivationName returns the activation name of the current version.
+func (r *Registry) ActivationName() string {
+	return r.current.Load().Network.ActivationName()
+}
+
+// Metadata returns the metadata of the current version.
+func (r *Registry) Metadata() map[string]string {
+	return r.current.Load().Network.Metadata()
+}
+
+// Version returns the ID of the current version.
+func (r *Registry) Version() string {
+	return r.current.Load().ID
+}
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"github.com/lnashier/gonet/feedforward"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// write saves a version to dir, modified i minutes after epoch.
+func write(t *testing.T, dir, name string, i int) {
+	t.Helper()
+	nn := feedforward.New(feedforward.Shapes([]int{2, 3, 1}), feedforward.NamedActivation("sigmoid"))
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nn.SaveJSON(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, dir, name, i)
+}
+
+func touch(t *testing.T, dir, name string, i int) {
+	t.Helper()
+	mt := epoch.Add(time.Duration(i) * time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, name), mt, mt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(r *Registry) []string {
+	var ids []string
+	for _, v := range r.Versions() {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func assert(t *testing.T, r *Registry, current string, versions ...string) {
+	t.Helper()
+	if got := r.Current().ID; got != current {
+		t.Errorf("current %s, want %s", got, current)
+	}
+	if got := ids(r); !slices.Equal(got, versions) {
+		t.Errorf("versions %v, want %v", got, versions)
+	}
+}
+
+func TestKeepPinRollback(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"v1", "v2", "v3", "v4"} {
+		write(t, dir, name, i)
+	}
+	var changes []string
+	r, err := New(dir, Keep(2), OnChange(func(v *Version) {
+		changes = append(changes, v.ID)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r, "v4", "v3", "v4")
+
+	if err := r.Pin("v1"); err == nil {
+		t.Error("pinned a version not loaded")
+	}
+	if err := r.Pin("v3"); err != nil {
+		t.Fatal(err)
+	}
+	// the pinned version stays current and is kept aside
+	write(t, dir, "v5", 4)
+	write(t, dir, "v6", 5)
+	if err := r.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r, "v3", "v3", "v5", "v6")
+
+	r.Unpin()
+	assert(t, r, "v6", "v5", "v6")
+
+	if err := r.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r, "v5", "v5", "v6")
+	if r.Pinned() != "v5" {
+		t.Errorf("pinned %q, want v5", r.Pinned())
+	}
+	if err := r.Rollback(); err == nil {
+		t.Error("rolled back past the oldest version")
+	}
+
+	if want := []string{"v4", "v3", "v6", "v5"}; !slices.Equal(changes, want) {
+		t.Errorf("changes %v, want %v", changes, want)
+	}
+}
+
+func TestScanInvalid(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "v1", 0)
+	var errs []error
+	r, err := New(dir, OnError(func(err error) {
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// truncated and activation-less files are reported and skipped
+	if err := os.WriteFile(filepath.Join(dir, "v2"), []byte(`{"shapes": [2, 3`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, dir, "v2", 1)
+	nn := feedforward.New(feedforward.Shapes([]int{2, 3, 1}))
+	f, err := os.Create(filepath.Join(dir, "v3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nn.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	touch(t, dir, "v3", 2)
+
+	if err := r.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r, "v1", "v1")
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want 2", errs)
+	}
+
+	// unchanged files aren't retried, fixed ones are loaded
+	if err := r.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want 2", errs)
+	}
+	write(t, dir, "v2", 3)
+	if err := r.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, r, "v2", "v1", "v2")
+}
+
+func TestNewWithoutModels(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "v1"), []byte("not a model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(dir); err == nil {
+		t.Fatal("created a registry without models")
+	}
+}
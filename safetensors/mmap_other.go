@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package safetensors
+
+import "os"
+
+// mmap reads the named file into memory where mapping isn't supported.
+func mmap(name string) ([]byte, func() error, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, func() error { return nil }, nil
+}
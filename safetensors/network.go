@@ -0,0 +1,218 @@
+package safetensors
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"io"
+	"math"
+	"slices"
+	"unsafe"
+)
+
+const (
+	formatKey     = "format"
+	format        = "gonet/feedforward"
+	shapesKey     = "shapes"
+	activationKey = "activation"
+)
+
+func weightName(l int) string {
+	return fmt.Sprintf("layers.%d.weight", l)
+}
+
+func biasName(l int) string {
+	return fmt.Sprintf("layers.%d.bias", l)
+}
+
+// Save writes the network with dtype F32 or F64 matching T.
+func Save[T fns.Float](w io.Writer, nn *feedforward.Net[T]) error {
+	var zero T
+	width := int(unsafe.Sizeof(zero))
+	dtype := fmt.Sprintf("F%d", 8*width)
+
+	shapes := nn.Shapes()
+	metadata := map[string]string{}
+	for k, v := range nn.Metadata() {
+		metadata[k] = v
+	}
+	s, _ := json.Marshal(shapes)
+	metadata[formatKey] = format
+	metadata[shapesKey] = string(s)
+	if name := nn.ActivationName(); name != "" {
+		metadata[activationKey] = name
+	}
+
+	tensors := map[string]*tensorInfo{}
+	var data [][]T
+	offset := 0
+	add := func(name string, shape []int, values []T) {
+		tensors[name] = &tensorInfo{
+			DType:       dtype,
+			Shape:       shape,
+			DataOffsets: [2]int{offset, offset + len(values)*width},
+		}
+		offset += len(values) * width
+		data = append(data, values)
+	}
+	for l := range len(shapes) - 1 {
+		add(weightName(l), []int{shapes[l+1], shapes[l]}, nn.Weights(l))
+		add(biasName(l), []int{shapes[l+1]}, nn.Biases(l))
+	}
+
+	b, err := header(tensors, metadata)
+	if err != nil {
+		return err
+	}
+	for _, values := range data {
+		for _, v := range values {
+			if width == 4 {
+				b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v)))
+			} else {
+				b = binary.LittleEndian.AppendUint64(b, math.Float64bits(float64(v)))
+			}
+		}
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Load reads a network, copying weights into precision T.
+// The activation recorded in the file is set unless options override it.
+func Load[T fns.Float](r io.Reader, opt ...feedforward.NetworkOpt) (*feedforward.Net[T], error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return build[T](f, false, opt)
+}
+
+// build constructs a network from a parsed file.
+// With zeroCopy, layers alias the file data, which must then hold T values in native byte order.
+func build[T fns.Float](f *file, zeroCopy bool, opt []feedforward.NetworkOpt) (*feedforward.Net[T], error) {
+	if f.metadata[formatKey] != format {
+		return nil, fmt.Errorf("safetensors: not a %s file", format)
+	}
+	var shapes []int
+	if err := json.Unmarshal([]byte(f.metadata[shapesKey]), &shapes); err != nil || len(shapes) < 2 {
+		return nil, fmt.Errorf("safetensors: invalid shapes %q", f.metadata[shapesKey])
+	}
+	if len(f.tensors) != 2*(len(shapes)-1) {
+		return nil, fmt.Errorf("safetensors: %d tensors for shapes %v", len(f.tensors), shapes)
+	}
+
+	weights := make([][]T, len(shapes)-1)
+	biases := make([][]T, len(shapes)-1)
+	for l := range weights {
+		var err error
+		if weights[l], err = tensor[T](f, weightName(l), []int{shapes[l+1], shapes[l]}, zeroCopy); err != nil {
+			return nil, err
+		}
+		if biases[l], err = tensor[T](f, biasName(l), []int{shapes[l+1]}, zeroCopy); err != nil {
+			return nil, err
+		}
+	}
+
+	var opts []feedforward.NetworkOpt
+	if name := f.metadata[activationKey]; name != "" {
+		if _, _, ok := fns.Activation(name); ok {
+			opts = append(opts, feedforward.NamedActivation(name))
+		}
+	}
+	metadata := map[string]string{}
+	for k, v := range f.metadata {
+		if k != formatKey && k != shapesKey && k != activationKey {
+			metadata[k] = v
+		}
+	}
+	opts = append(opts, feedforward.Metadata(metadata))
+
+	return feedforward.FromWeights(weights, biases, append(opts, opt...)...)
+}
+
+// tensor returns the values of the named tensor after checking its shape.
+func tensor[T fns.Float](f *file, name string, shape []int, zeroCopy bool) ([]T, error) {
+	t, ok := f.tensors[name]
+	if !ok {
+		return nil, fmt.Errorf("safetensors: missing tensor %s", name)
+	}
+	if !slices.Equal(t.Shape, shape) {
+		return nil, fmt.Errorf("safetensors: tensor %s has shape %v, want %v", name, t.Shape, shape)
+	}
+	b := f.bytes(t)
+
+	var zero T
+	if zeroCopy {
+		if dtypeSizes[t.DType] != int(unsafe.Sizeof(zero)) {
+			return nil, fmt.Errorf("safetensors: tensor %s is %s, can't map it as float%d", name, t.DType, 8*unsafe.Sizeof(zero))
+		}
+		if len(b) == 0 {
+			return []T{}, nil
+		}
+		return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(zero))), nil
+	}
+
+	values := make([]T, 0, len(b)/dtypeSizes[t.DType])
+	switch t.DType {
+	case "F32":
+		for i := 0; i < len(b); i += 4 {
+			values = append(values, T(math.Float32frombits(binary.LittleEndian.Uint32(b[i:]))))
+		}
+	case "F64":
+		for i := 0; i < len(b); i += 8 {
+			values = append(values, T(math.Float64frombits(binary.LittleEndian.Uint64(b[i:]))))
+		}
+	}
+	return values, nil
+}
+
+// Mapped is a network whose weights live in a memory-mapped file.
+type Mapped[T fns.Float] struct {
+	*feedforward.Net[T]
+	unmap func() error
+}
+
+// Close releases the mapping. The network must not be used afterwards.
+func (m *Mapped[T]) Close() error {
+	return m.unmap()
+}
+
+// Map maps the named file into memory and builds a network on top of it without copying weights.
+// The file must hold weights of precision T; mapping is copy-on-write, so the file is never modified.
+// Where mapping isn't possible (unaligned data, big-endian hosts, unsupported platforms), weights are copied.
+func Map[T fns.Float](name string, opt ...feedforward.NetworkOpt) (*Mapped[T], error) {
+	b, unmap, err := mmap(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(b)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+
+	var zero T
+	zeroCopy := nativeLittleEndian && f.offset%int(unsafe.Sizeof(zero)) == 0 && len(b) > 0 &&
+		uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(zero) == 0
+	nn, err := build[T](f, zeroCopy, opt)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	if !zeroCopy {
+		// nothing refers to the mapping
+		if err := unmap(); err != nil {
+			return nil, err
+		}
+		unmap = func() error { return nil }
+	}
+	return &Mapped[T]{Net: nn, unmap: unmap}, nil
+}
+
+var nativeLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
@@ -0,0 +1,155 @@
+// Package safetensors reads and writes networks in the safetensors format
+// (https://github.com/huggingface/safetensors), optionally memory-mapping weights for inference.
+//
+// Layer l, 0 being the first hidden layer, is stored as two tensors:
+//
+//	layers.<l>.weight  (nodes, inputs)
+//	layers.<l>.bias    (nodes)
+//
+// Shapes, activation name and network metadata go to the __metadata__ section.
+package safetensors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// maxHeaderSize bounds the header read from untrusted files.
+const maxHeaderSize = 100 << 20
+
+const metadataKey = "__metadata__"
+
+var dtypeSizes = map[string]int{
+	"F32": 4,
+	"F64": 8,
+}
+
+type tensorInfo struct {
+	DType       string `json:"dtype"`
+	Shape       []int  `json:"shape"`
+	DataOffsets [2]int `json:"data_offsets"`
+}
+
+// file is a parsed safetensors file, data being the byte buffer following the header.
+type file struct {
+	tensors  map[string]*tensorInfo
+	metadata map[string]string
+	data     []byte
+	// offset of data within the file
+	offset int
+}
+
+// parse validates the header of a safetensors file.
+// Tensors must exactly tile the data buffer: no gaps, overlaps or trailing bytes,
+// and each tensor must span as many bytes as its shape and dtype require.
+func parse(b []byte) (*file, error) {
+	if len(b) < 8 {
+		return nil, errors.New("safetensors: file too short")
+	}
+	size := binary.LittleEndian.Uint64(b)
+	if size > maxHeaderSize || size > uint64(len(b)-8) {
+		return nil, fmt.Errorf("safetensors: header size %d exceeds file size %d", size, len(b))
+	}
+	header := b[8 : 8+size]
+	if len(header) == 0 || header[0] != '{' {
+		return nil, errors.New("safetensors: header is not a JSON object")
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(header, &raw); err != nil {
+		return nil, fmt.Errorf("safetensors: header: %w", err)
+	}
+
+	f := &file{
+		tensors: map[string]*tensorInfo{},
+		data:    b[8+size:],
+		offset:  8 + int(size),
+	}
+	for name, v := range raw {
+		if name == metadataKey {
+			if err := json.Unmarshal(v, &f.metadata); err != nil {
+				return nil, fmt.Errorf("safetensors: metadata: %w", err)
+			}
+			continue
+		}
+		t := &tensorInfo{}
+		d := json.NewDecoder(bytes.NewReader(v))
+		d.DisallowUnknownFields()
+		if err := d.Decode(t); err != nil {
+			return nil, fmt.Errorf("safetensors: tensor %s: %w", name, err)
+		}
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("safetensors: tensor %s: %w", name, err)
+		}
+		f.tensors[name] = t
+	}
+
+	infos := make([]*tensorInfo, 0, len(f.tensors))
+	for _, t := range f.tensors {
+		infos = append(infos, t)
+	}
+	slices.SortFunc(infos, func(a, b *tensorInfo) int {
+		return a.DataOffsets[0] - b.DataOffsets[0]
+	})
+	end := 0
+	for _, t := range infos {
+		if t.DataOffsets[0] != end {
+			return nil, fmt.Errorf("safetensors: tensor data at %d doesn't follow previous tensor ending at %d", t.DataOffsets[0], end)
+		}
+		end = t.DataOffsets[1]
+	}
+	if end != len(f.data) {
+		return nil, fmt.Errorf("safetensors: tensors span %d bytes, file has %d", end, len(f.data))
+	}
+
+	return f, nil
+}
+
+func (t *tensorInfo) validate() error {
+	width, ok := dtypeSizes[t.DType]
+	if !ok {
+		return fmt.Errorf("unsupported dtype %q", t.DType)
+	}
+	n := width
+	for _, d := range t.Shape {
+		if d < 0 || (d > 0 && n > math.MaxInt32/d) {
+			return fmt.Errorf("invalid shape %v", t.Shape)
+		}
+		n *= d
+	}
+	begin, end := t.DataOffsets[0], t.DataOffsets[1]
+	if begin < 0 || end < begin || end-begin != n {
+		return fmt.Errorf("data offsets %v don't match %d bytes of %s%v", t.DataOffsets, n, t.DType, t.Shape)
+	}
+	return nil
+}
+
+// bytes returns the data of the tensor.
+func (f *file) bytes(t *tensorInfo) []byte {
+	return f.data[t.DataOffsets[0]:t.DataOffsets[1]]
+}
+
+// header encodes tensors and metadata, padded with spaces so that data starts 8-byte aligned.
+func header(tensors map[string]*tensorInfo, metadata map[string]string) ([]byte, error) {
+	entries := map[string]any{}
+	for name, t := range tensors {
+		entries[name] = t
+	}
+	if len(metadata) > 0 {
+		entries[metadataKey] = metadata
+	}
+	h, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	h = append(h, strings.Repeat(" ", (8-len(h)%8)%8)...)
+
+	b := binary.LittleEndian.AppendUint64(nil, uint64(len(h)))
+	return append(b, h...), nil
+}
@@ -0,0 +1,213 @@
+package safetensors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/fns"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// encode writes a safetensors file of the given header and data, unchecked.
+func encode(t *testing.T, header map[string]any, data []byte) []byte {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := binary.LittleEndian.AppendUint64(nil, uint64(len(h)))
+	return append(append(b, h...), data...)
+}
+
+func tensorHeader(dtype string, shape []int, begin, end int) map[string]any {
+	return map[string]any{"dtype": dtype, "shape": shape, "data_offsets": []int{begin, end}}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := make([]byte, 16)
+	tests := map[string][]byte{
+		"truncated header length": {1, 0, 0, 0},
+		"header past end":         binary.LittleEndian.AppendUint64(nil, 1000),
+		"header not an object":    append(binary.LittleEndian.AppendUint64(nil, 2), "[]"...),
+		"overlap": encode(t, map[string]any{
+			"a": tensorHeader("F32", []int{2}, 0, 8),
+			"b": tensorHeader("F32", []int{2}, 4, 12),
+		}, data[:12]),
+		"gap": encode(t, map[string]any{
+			"a": tensorHeader("F32", []int{2}, 0, 8),
+			"b": tensorHeader("F32", []int{1}, 12, 16),
+		}, data),
+		"trailing bytes": encode(t, map[string]any{
+			"a": tensorHeader("F32", []int{2}, 0, 8),
+		}, data[:12]),
+		"data past end": encode(t, map[string]any{
+			"a": tensorHeader("F64", []int{2}, 0, 16),
+		}, data[:8]),
+		"unsupported dtype": encode(t, map[string]any{
+			"a": tensorHeader("F16", []int{4}, 0, 8),
+		}, data[:8]),
+		"shape and offsets": encode(t, map[string]any{
+			"a": tensorHeader("F32", []int{3}, 0, 8),
+		}, data[:8]),
+		"negative shape": encode(t, map[string]any{
+			"a": tensorHeader("F32", []int{-2}, 0, 8),
+		}, data[:8]),
+		"unknown field": encode(t, map[string]any{
+			"a": map[string]any{"dtype": "F32", "shape": []int{2}, "data_offsets": []int{0, 8}, "extra": 1},
+		}, data[:8]),
+	}
+	for name, b := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parse(b)
+			if err == nil {
+				t.Fatal("parsed an invalid file")
+			}
+			t.Log(err)
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	nn := testNet[float32]()
+	var buf bytes.Buffer
+	if err := Save(&buf, nn); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	// the file is valid, the network it describes isn't
+	if _, err := parse(valid); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]func(f *file){
+		"format": func(f *file) {
+			f.metadata[formatKey] = "other"
+		},
+		"shapes": func(f *file) {
+			f.metadata[shapesKey] = "[3, 4]"
+		},
+		"missing tensor": func(f *file) {
+			f.tensors["layers.9.bias"] = f.tensors[biasName(0)]
+			delete(f.tensors, biasName(0))
+		},
+		"tensor shape": func(f *file) {
+			w := *f.tensors[weightName(0)]
+			w.Shape = []int{w.Shape[1], w.Shape[0]}
+			f.tensors[weightName(0)] = &w
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := parse(valid)
+			mutate(f)
+			if _, err := build[float32](f, false, nil); err == nil {
+				t.Fatal("built an invalid network")
+			}
+		})
+	}
+
+	// truncating a valid file anywhere breaks it
+	for _, n := range []int{0, 7, 8, 20, len(valid) - 1} {
+		if _, err := Load[float32](bytes.NewReader(valid[:n])); err == nil {
+			t.Errorf("loaded a file truncated to %d bytes", n)
+		}
+	}
+}
+
+func testNet[T fns.Float]() *feedforward.Net[T] {
+	rand.Seed(1)
+	return feedforward.NewNet[T](
+		feedforward.Shapes([]int{4, 5, 3}),
+		feedforward.NamedActivation("tanh"),
+		feedforward.Metadata(map[string]string{"dataset": "test"}),
+	)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Run("float32", testRoundTrip[float32])
+	t.Run("float64", testRoundTrip[float64])
+}
+
+func testRoundTrip[T fns.Float](t *testing.T) {
+	nn := testNet[T]()
+	name := filepath.Join(t.TempDir(), "net.safetensors")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(f, nn); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load[T](bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSame(t, loaded, nn)
+
+	mapped, err := Map[T](name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+	assertSame(t, mapped.Net, nn)
+
+	input := []T{0.5, -1, 0.25, 2}
+	if got, want := mapped.Predict(input), nn.Predict(input); !slices.Equal(got, want) {
+		t.Fatalf("mapped network predicts %v, want %v", got, want)
+	}
+
+	// mapping is copy-on-write, the file is left as is
+	if err := mapped.SetWeights(0, make([]T, 20), make([]T, 5)); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, b) {
+		t.Fatal("file modified through the mapping")
+	}
+}
+
+func assertSame[T fns.Float](t *testing.T, got, want *feedforward.Net[T]) {
+	t.Helper()
+	if !slices.Equal(got.Shapes(), want.Shapes()) || got.ActivationName() != want.ActivationName() {
+		t.Fatalf("got %v %s, want %v %s", got.Shapes(), got.ActivationName(), want.Shapes(), want.ActivationName())
+	}
+	if got.Metadata()["dataset"] != "test" || len(got.Metadata()) != 1 {
+		t.Fatalf("got metadata %v", got.Metadata())
+	}
+	for l := range len(want.Shapes()) - 1 {
+		if !slices.Equal(got.Weights(l), want.Weights(l)) || !slices.Equal(got.Biases(l), want.Biases(l)) {
+			t.Fatalf("layer %d differs", l)
+		}
+	}
+}
+
+// Headers are padded so that mapped data is aligned.
+func TestHeaderAlignment(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Save(&buf, testNet[float64]()); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.offset%8 != 0 {
+		t.Fatalf("data at offset %d", f.offset)
+	}
+}
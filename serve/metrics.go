@@ -0,0 +1,76 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metrics counts prediction requests per model and status code.
+type metrics struct {
+	mu     sync.Mutex
+	models map[string]*modelMetrics
+}
+
+type modelMetrics struct {
+	requests    map[int]uint64 // by status code
+	predictions uint64
+	seconds     float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{models: map[string]*modelMetrics{}}
+}
+
+func (m *metrics) observe(name string, code, predictions int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm, ok := m.models[name]
+	if !ok {
+		mm = &modelMetrics{requests: map[int]uint64{}}
+		m.models[name] = mm
+	}
+	mm.requests[code]++
+	mm.predictions += uint64(predictions)
+	mm.seconds += d.Seconds()
+}
+
+// write prints the metrics in Prometheus text format.
+func (m *metrics) write(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.models))
+	for name := range m.models {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP gonet_requests_total Prediction requests by model and status code.")
+	fmt.Fprintln(w, "# TYPE gonet_requests_total counter")
+	for _, name := range names {
+		mm := m.models[name]
+		codes := make([]int, 0, len(mm.requests))
+		for code := range mm.requests {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "gonet_requests_total{model=%s,code=\"%d\"} %d\n", strconv.Quote(name), code, mm.requests[code])
+		}
+	}
+	fmt.Fprintln(w, "# HELP gonet_predictions_total Inputs predicted by model.")
+	fmt.Fprintln(w, "# TYPE gonet_predictions_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "gonet_predictions_total{model=%s} %d\n", strconv.Quote(name), m.models[name].predictions)
+	}
+	fmt.Fprintln(w, "# HELP gonet_request_seconds_total Time spent serving prediction requests by model.")
+	fmt.Fprintln(w, "# TYPE gonet_request_seconds_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "gonet_request_seconds_total{model=%s} %g\n", strconv.Quote(name), m.models[name].seconds)
+	}
+}
@@ -0,0 +1,34 @@
+package serve
+
+type Opt func(*serverOpts)
+
+type serverOpts struct {
+	maxBatchSize int
+	maxBodyBytes int64
+}
+
+var defaultServerOpts = serverOpts{
+	maxBatchSize: 1024,
+	maxBodyBytes: 32 << 20,
+}
+
+func (s *serverOpts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// MaxBatchSize limits the number of inputs of a prediction request, 0 meaning no limit.
+// Defaults to 1024.
+func MaxBatchSize(v int) Opt {
+	return func(s *serverOpts) {
+		s.maxBatchSize = v
+	}
+}
+
+// MaxBodyBytes limits the size of a prediction request. Defaults to 32 MiB.
+func MaxBodyBytes(v int64) Opt {
+	return func(s *serverOpts) {
+		s.maxBodyBytes = v
+	}
+}
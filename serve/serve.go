@@ -0,0 +1,274 @@
+// Package serve exposes networks over HTTP with JSON prediction endpoints.
+//
+//	POST /v1/models/{name}/predict  {"inputs": [[0, 1], [1, 1]]} -> {"outputs": [[0.98], [0.01]]}
+//	GET  /v1/models                 names, shapes, activations and metadata of the models
+//	GET  /v1/models/{name}          the same for one model
+//	GET  /healthz                   200 while the server runs
+//	GET  /readyz                    200 once a model is served, 503 before
+//	GET  /metrics                   request counters and latencies in Prometheus text format
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/help"
+	"github.com/lnashier/gonet/registry"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Predictor is a model that can be served. Predict must be safe for concurrent use.
+// Models reporting their ActivationName, Metadata or Version have them listed,
+// those with a PredictBatch method predict requests in one pass.
+// Registries are resolved to their current version once per request, see registry.Registry.
+type Predictor interface {
+	Predict(input []float64) []float64
+	Shapes() []int
+}
+
+// Server serves predictions of named models. It is safe for concurrent use,
+// models can be added and removed while serving.
+type Server struct {
+	mu      sync.RWMutex
+	models  map[string]Predictor
+	mux     *http.ServeMux
+	metrics *metrics
+	opts    serverOpts
+}
+
+// New returns a server without models, not ready until one is added.
+func New(opt ...Opt) *Server {
+	opts := defaultServerOpts
+	opts.apply(opt)
+
+	s := &Server{
+		models:  map[string]Predictor{},
+		mux:     http.NewServeMux(),
+		metrics: newMetrics(),
+		opts:    opts,
+	}
+	s.mux.HandleFunc("POST /v1/models/{name}/predict", s.predict)
+	s.mux.HandleFunc("GET /v1/models", s.list)
+	s.mux.HandleFunc("GET /v1/models/{name}", s.info)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.mux.HandleFunc("GET /readyz", s.ready)
+	s.mux.HandleFunc("GET /metrics", s.metrics.write)
+	return s
+}
+
+// Add serves p under name, replacing the model served under that name if any.
+func (s *Server) Add(name string, p Predictor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[name] = p
+}
+
+// Load reads the network saved in the named file, gob or JSON, and serves it under name.
+func (s *Server) Load(name, file string, opt ...feedforward.NetworkOpt) error {
+	nn, err := help.LoadFeedforward(file, opt...)
+	if err != nil {
+		return err
+	}
+	if nn.Activation() == nil {
+		return fmt.Errorf("%s doesn't record its activation, set one with opt", file)
+	}
+	s.Add(name, nn)
+	return nil
+}
+
+// Remove stops serving the named model.
+func (s *Server) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.models, name)
+}
+
+// Models returns the names of the served models in order.
+func (s *Server) Models() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.models))
+	for name := range s.models {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// model returns the model served under name, the current version of registries,
+// so that a request is checked and predicted by one network while versions are swapped.
+func (s *Server) model(name string) (Predictor, bool) {
+	s.mu.RLock()
+	p, ok := s.models[name]
+	s.mu.RUnlock()
+	if r, isRegistry := p.(interface{ Current() *registry.Version }); isRegistry {
+		v := r.Current()
+		return version{v.Network, v.ID}, ok
+	}
+	return p, ok
+}
+
+// version is a registry version as served.
+type version struct {
+	*feedforward.Network
+	id string
+}
+
+func (v version) Version() string {
+	return v.id
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// PredictRequest is the body of a prediction request, a batch of inputs.
+type PredictRequest struct {
+	Inputs [][]float64 `json:"inputs"`
+}
+
+// PredictResponse holds the outputs of a prediction request, in the order of its inputs.
+type PredictResponse struct {
+	Outputs [][]float64 `json:"outputs"`
+}
+
+// ModelInfo describes a served model.
+type ModelInfo struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version,omitempty"`
+	Shapes     []int             `json:"shapes"`
+	Activation string            `json:"activation,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) predict(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := r.PathValue("name")
+	code, rows := s.servePredict(w, r, name)
+	if code == http.StatusNotFound {
+		// unknown names are counted together, clients can't grow the metrics
+		name = ""
+	}
+	s.metrics.observe(name, code, rows, time.Since(start))
+}
+
+func (s *Server) servePredict(w http.ResponseWriter, r *http.Request, name string) (code, rows int) {
+	p, ok := s.model(name)
+	if !ok {
+		return writeError(w, http.StatusNotFound, fmt.Errorf("model not found: %s", name)), 0
+	}
+
+	var req PredictRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.opts.maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return writeError(w, http.StatusRequestEntityTooLarge, err), 0
+		}
+		return writeError(w, http.StatusBadRequest, err), 0
+	}
+	if len(req.Inputs) == 0 {
+		return writeError(w, http.StatusBadRequest, errors.New("no inputs")), 0
+	}
+	if s.opts.maxBatchSize > 0 && len(req.Inputs) > s.opts.maxBatchSize {
+		return writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d inputs exceed the batch size of %d", len(req.Inputs), s.opts.maxBatchSize)), 0
+	}
+	size := p.Shapes()[0]
+	for i, input := range req.Inputs {
+		if len(input) != size {
+			return writeError(w, http.StatusBadRequest, fmt.Errorf("input %d has %d values, model %s takes %d", i, len(input), name, size)), 0
+		}
+	}
+
+	var resp PredictResponse
+	if b, ok := p.(interface {
+		PredictBatch(inputs [][]float64) [][]float64
+	}); ok {
+		resp.Outputs = b.PredictBatch(req.Inputs)
+	} else {
+		resp.Outputs = make([][]float64, len(req.Inputs))
+		for i, input := range req.Inputs {
+			resp.Outputs[i] = p.Predict(input)
+		}
+	}
+	return writeJSON(w, http.StatusOK, resp), len(req.Inputs)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	names := s.Models()
+	infos := make([]ModelInfo, 0, len(names))
+	for _, name := range names {
+		if p, ok := s.model(name); ok {
+			infos = append(infos, info(name, p))
+		}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Models []ModelInfo `json:"models"`
+	}{infos})
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	p, ok := s.model(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("model not found: %s", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, info(name, p))
+}
+
+func (s *Server) ready(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	n := len(s.models)
+	s.mu.RUnlock()
+	if n == 0 {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no models"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func info(name string, p Predictor) ModelInfo {
+	mi := ModelInfo{Name: name, Shapes: p.Shapes()}
+	if v, ok := p.(interface{ Version() string }); ok {
+		mi.Version = v.Version()
+	}
+	if a, ok := p.(interface{ ActivationName() string }); ok {
+		mi.Activation = a.ActivationName()
+	}
+	if m, ok := p.(interface{ Metadata() map[string]string }); ok {
+		mi.Metadata = m.Metadata()
+	}
+	return mi
+}
+
+// writeJSON encodes v before writing the header, failing with 500 on values
+// JSON can't encode, such as NaN outputs.
+func writeJSON(w http.ResponseWriter, code int, v any) int {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		code = http.StatusInternalServerError
+		buf.Reset()
+		json.NewEncoder(&buf).Encode(errorResponse{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+	return code
+}
+
+func writeError(w http.ResponseWriter, code int, err error) int {
+	return writeJSON(w, code, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,172 @@
+package serve
+
+import (
+	"encoding/json"
+	"github.com/lnashier/gonet/feedforward"
+	"github.com/lnashier/gonet/help"
+	"github.com/lnashier/gonet/registry"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testNet() *feedforward.Network {
+	rand.Seed(1)
+	return feedforward.New(feedforward.Shapes([]int{2, 3, 1}), feedforward.NamedActivation("sigmoid"))
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestPredict(t *testing.T) {
+	nn := testNet()
+	s := New(MaxBatchSize(2), MaxBodyBytes(64))
+	s.Add("xor", nn)
+
+	w := do(t, s, http.MethodPost, "/v1/models/xor/predict", `{"inputs": [[0, 1], [1, 1]]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	var resp PredictResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	for i, input := range [][]float64{{0, 1}, {1, 1}} {
+		if want := nn.Predict(input); len(resp.Outputs[i]) != 1 || resp.Outputs[i][0] != want[0] {
+			t.Errorf("input %d: got %v, want %v", i, resp.Outputs[i], want)
+		}
+	}
+
+	tests := map[string]struct {
+		path, body string
+		code       int
+	}{
+		"width":         {"/v1/models/xor/predict", `{"inputs": [[0, 1], [1]]}`, http.StatusBadRequest},
+		"no inputs":     {"/v1/models/xor/predict", `{"inputs": []}`, http.StatusBadRequest},
+		"unknown field": {"/v1/models/xor/predict", `{"input": [[0, 1]]}`, http.StatusBadRequest},
+		"batch size":    {"/v1/models/xor/predict", `{"inputs": [[0, 1], [1, 1], [1, 0]]}`, http.StatusRequestEntityTooLarge},
+		"body size":     {"/v1/models/xor/predict", `{"inputs": [[0.000000000001, 1.000000000001], [1.000000000001, 1]]}`, http.StatusRequestEntityTooLarge},
+		"unknown model": {"/v1/models/and/predict", `{"inputs": [[0, 1]]}`, http.StatusNotFound},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := do(t, s, http.MethodPost, test.path, test.body)
+			if w.Code != test.code {
+				t.Fatalf("got %d, want %d: %s", w.Code, test.code, w.Body)
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+				t.Fatalf("got %s, want an error", w.Body)
+			}
+		})
+	}
+}
+
+// nanPredictor predicts outputs JSON can't encode.
+type nanPredictor struct{}
+
+func (nanPredictor) Predict([]float64) []float64 { return []float64{math.NaN()} }
+func (nanPredictor) Shapes() []int               { return []int{1, 1} }
+
+func TestPredictUnencodable(t *testing.T) {
+	s := New()
+	s.Add("nan", nanPredictor{})
+	w := do(t, s, http.MethodPost, "/v1/models/nan/predict", `{"inputs": [[0]]}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+		t.Fatalf("got %s, want an error", w.Body)
+	}
+}
+
+func TestReady(t *testing.T) {
+	s := New()
+	if w := do(t, s, http.MethodGet, "/healthz", ""); w.Code != http.StatusOK {
+		t.Fatalf("healthz: got %d", w.Code)
+	}
+	if w := do(t, s, http.MethodGet, "/readyz", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz without models: got %d", w.Code)
+	}
+	s.Add("xor", testNet())
+	if w := do(t, s, http.MethodGet, "/readyz", ""); w.Code != http.StatusOK {
+		t.Fatalf("readyz: got %d", w.Code)
+	}
+	s.Remove("xor")
+	if w := do(t, s, http.MethodGet, "/readyz", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz once removed: got %d", w.Code)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "xor.gob")
+	if err := help.Save(file, testNet()); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	if err := s.Load("xor", file); err == nil {
+		t.Fatal("loaded a model without activation")
+	}
+	if err := s.Load("xor", file, feedforward.NamedActivation("sigmoid")); err != nil {
+		t.Fatal(err)
+	}
+	if w := do(t, s, http.MethodPost, "/v1/models/xor/predict", `{"inputs": [[0, 1]]}`); w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	if err := help.Save(filepath.Join(dir, "v1.json"), testNet()); err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	s.Add("xor", r)
+
+	if w := do(t, s, http.MethodPost, "/v1/models/xor/predict", `{"inputs": [[0, 1]]}`); w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	w := do(t, s, http.MethodGet, "/v1/models/xor", "")
+	var mi ModelInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &mi); err != nil {
+		t.Fatal(err)
+	}
+	if mi.Version != "v1.json" || mi.Activation != "sigmoid" || len(mi.Shapes) != 3 {
+		t.Fatalf("got %+v", mi)
+	}
+
+	// a wider version swapped in is checked against its own width
+	rand.Seed(2)
+	wide := feedforward.New(feedforward.Shapes([]int{3, 2, 1}), feedforward.NamedActivation("sigmoid"))
+	if err := help.Save(filepath.Join(dir, "v2.json"), wide); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "v2.json"), r.Current().ModTime.Add(1), r.Current().ModTime.Add(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if w := do(t, s, http.MethodPost, "/v1/models/xor/predict", `{"inputs": [[0, 1]]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	if w := do(t, s, http.MethodPost, "/v1/models/xor/predict", `{"inputs": [[0, 1, 1]]}`); w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,344 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columns of a CSV history, followed by one per metric.
+// Validation metrics are prefixed with validationPrefix.
+var columns = []string{
+	"epoch", "start", "end", "inputs",
+	"train_loss", "validation_loss", "validation_inputs",
+	"learning_rate", "grad_norm", "weight_norm",
+}
+
+const validationPrefix = "validation_"
+
+// WriteJSON writes the history as JSON.
+// Losses, norms and metrics that are NaN or infinite are written as the strings "NaN", "+Inf" and "-Inf".
+func (t *Training) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// ReadJSON reads a history written by WriteJSON.
+func ReadJSON(r io.Reader) (*Training, error) {
+	t := &Training{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// epoch has the fields of Epoch without its JSON methods.
+type epoch Epoch
+
+// epochJSON is an Epoch as written to JSON, its floats shadowing those of epoch.
+type epochJSON struct {
+	*epoch
+	TrainLoss         jsonFloat            `json:"trainLoss"`
+	ValidationLoss    jsonFloat            `json:"validationLoss"`
+	Metrics           map[string]jsonFloat `json:"metrics,omitempty"`
+	ValidationMetrics map[string]jsonFloat `json:"validationMetrics,omitempty"`
+	LearningRate      jsonFloat            `json:"learningRate"`
+	GradNorm          jsonFloat            `json:"gradNorm"`
+	WeightNorm        jsonFloat            `json:"weightNorm"`
+}
+
+func (e Epoch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(epochJSON{
+		epoch:             (*epoch)(&e),
+		TrainLoss:         jsonFloat(e.TrainLoss),
+		ValidationLoss:    jsonFloat(e.ValidationLoss),
+		Metrics:           jsonFloats(e.Metrics),
+		ValidationMetrics: jsonFloats(e.ValidationMetrics),
+		LearningRate:      jsonFloat(e.LearningRate),
+		GradNorm:          jsonFloat(e.GradNorm),
+		WeightNorm:        jsonFloat(e.WeightNorm),
+	})
+}
+
+func (e *Epoch) UnmarshalJSON(b []byte) error {
+	v := epochJSON{epoch: (*epoch)(e)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	e.TrainLoss = float64(v.TrainLoss)
+	e.ValidationLoss = float64(v.ValidationLoss)
+	e.Metrics = floats(v.Metrics)
+	e.ValidationMetrics = floats(v.ValidationMetrics)
+	e.LearningRate = float64(v.LearningRate)
+	e.GradNorm = float64(v.GradNorm)
+	e.WeightNorm = float64(v.WeightNorm)
+	return nil
+}
+
+// jsonFloat is a float64 written as a JSON number when finite, and as a string otherwise.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return json.Marshal(formatFloat(v))
+	}
+	return json.Marshal(v)
+}
+
+func (f *jsonFloat) UnmarshalJSON(b []byte) error {
+	var v float64
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		var err error
+		if v, err = strconv.ParseFloat(s, 64); err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+func jsonFloats(m map[string]float64) map[string]jsonFloat {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]jsonFloat, len(m))
+	for k, v := range m {
+		c[k] = jsonFloat(v)
+	}
+	return c
+}
+
+func floats(m map[string]jsonFloat) map[string]float64 {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]float64, len(m))
+	for k, v := range m {
+		c[k] = float64(v)
+	}
+	return c
+}
+
+// WriteCSV writes the history as CSV, one row per epoch.
+// Times are RFC 3339, the start and end of the run are those of its first and last epochs.
+func (t *Training) WriteCSV(w io.Writer) error {
+	metrics, validationMetrics := t.metricNames()
+	header := slices.Clone(columns)
+	header = append(header, metrics...)
+	for _, name := range validationMetrics {
+		header = append(header, validationPrefix+name)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range t.Epochs {
+		row := []string{
+			strconv.Itoa(e.ID),
+			formatTime(e.Start),
+			formatTime(e.End),
+			strconv.Itoa(e.Inputs),
+			formatFloat(e.TrainLoss),
+			formatFloat(e.ValidationLoss),
+			strconv.Itoa(e.ValidationInputs),
+			formatFloat(e.LearningRate),
+			formatFloat(e.GradNorm),
+			formatFloat(e.WeightNorm),
+		}
+		for _, name := range metrics {
+			row = append(row, formatMetric(e.Metrics, name))
+		}
+		for _, name := range validationMetrics {
+			row = append(row, formatMetric(e.ValidationMetrics, name))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads a history written by WriteCSV.
+func ReadCSV(r io.Reader) (*Training, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < len(columns) || !slices.Equal(header[:len(columns)], columns) {
+		return nil, fmt.Errorf("stats: unexpected CSV header %v", header)
+	}
+
+	t := &Training{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := parser{row: row}
+		e := &Epoch{
+			ID:               p.int(0),
+			Start:            p.time(1),
+			End:              p.time(2),
+			Inputs:           p.int(3),
+			TrainLoss:        p.float(4),
+			ValidationLoss:   p.float(5),
+			ValidationInputs: p.int(6),
+			LearningRate:     p.float(7),
+			GradNorm:         p.float(8),
+			WeightNorm:       p.float(9),
+		}
+		for i := len(columns); i < len(header); i++ {
+			if row[i] == "" {
+				continue
+			}
+			v := p.float(i)
+			if name, ok := strings.CutPrefix(header[i], validationPrefix); ok {
+				if e.ValidationMetrics == nil {
+					e.ValidationMetrics = map[string]float64{}
+				}
+				e.ValidationMetrics[name] = v
+			} else {
+				if e.Metrics == nil {
+					e.Metrics = map[string]float64{}
+				}
+				e.Metrics[header[i]] = v
+			}
+		}
+		if p.err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("stats: line %d: %w", line, p.err)
+		}
+		t.Add(e)
+	}
+	if len(t.Epochs) > 0 {
+		t.Start, t.End = t.Epochs[0].Start, t.Epochs[len(t.Epochs)-1].End
+	}
+	return t, nil
+}
+
+// Save writes the history to the named file, as CSV when the name ends with .csv and as JSON otherwise.
+func (t *Training) Save(name string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(name) == ".csv" {
+		err = t.WriteCSV(file)
+	} else {
+		err = t.WriteJSON(file)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Load reads a history saved by Save.
+func Load(name string) (*Training, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if filepath.Ext(name) == ".csv" {
+		return ReadCSV(file)
+	}
+	return ReadJSON(file)
+}
+
+// metricNames returns the names of the metrics of any epoch, and of the validation metrics, sorted.
+func (t *Training) metricNames() (metrics, validationMetrics []string) {
+	seen := map[string]bool{}
+	seenValidation := map[string]bool{}
+	for _, e := range t.Epochs {
+		for name := range e.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				metrics = append(metrics, name)
+			}
+		}
+		for name := range e.ValidationMetrics {
+			if !seenValidation[name] {
+				seenValidation[name] = true
+				validationMetrics = append(validationMetrics, name)
+			}
+		}
+	}
+	slices.Sort(metrics)
+	slices.Sort(validationMetrics)
+	return metrics, validationMetrics
+}
+
+// parser parses fields of a CSV row, keeping the first error.
+type parser struct {
+	row []string
+	err error
+}
+
+func (p *parser) int(i int) int {
+	v, err := strconv.Atoi(p.row[i])
+	p.fail(i, err)
+	return v
+}
+
+func (p *parser) float(i int) float64 {
+	v, err := strconv.ParseFloat(p.row[i], 64)
+	p.fail(i, err)
+	return v
+}
+
+func (p *parser) time(i int) time.Time {
+	if p.row[i] == "" {
+		return time.Time{}
+	}
+	v, err := time.Parse(time.RFC3339Nano, p.row[i])
+	p.fail(i, err)
+	return v
+}
+
+func (p *parser) fail(i int, err error) {
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %d: %w", i+1, err)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatMetric(m map[string]float64, name string) string {
+	v, ok := m[name]
+	if !ok {
+		return ""
+	}
+	return formatFloat(v)
+}
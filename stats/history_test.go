@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONNonFinite(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &Training{
+		Start: start,
+		End:   start.Add(2 * time.Second),
+		Epochs: []*Epoch{
+			{
+				ID: 0, Start: start, End: start.Add(time.Second), Inputs: 4,
+				TrainLoss: 0.25, ValidationLoss: math.NaN(), ValidationInputs: 2,
+				Metrics:           map[string]float64{"accuracy": 0.5},
+				ValidationMetrics: map[string]float64{"f1": math.NaN()},
+				LearningRate:      0.1, GradNorm: math.Inf(1), WeightNorm: 1.5,
+			},
+			{
+				ID: 1, Start: start.Add(time.Second), End: start.Add(2 * time.Second), Inputs: 4,
+				TrainLoss: math.Inf(1), LearningRate: 0.1, GradNorm: math.Inf(-1), WeightNorm: math.NaN(),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := want.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"NaN"`, `"+Inf"`, `"-Inf"`, `"trainLoss": 0.25`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("JSON doesn't contain %s:\n%s", s, buf.String())
+		}
+	}
+
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NaN never equals itself, compare their encodings instead
+	if !reflect.DeepEqual(encode(t, got), encode(t, want)) {
+		t.Fatalf("got %s, want %s", encode(t, got), encode(t, want))
+	}
+	if e := got.Epochs[1]; !math.IsInf(e.TrainLoss, 1) || !math.IsInf(e.GradNorm, -1) || !math.IsNaN(e.WeightNorm) {
+		t.Fatalf("got %+v", e)
+	}
+}
+
+func encode(t *testing.T, h *Training) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := h.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -1,19 +1,74 @@
+// Package stats holds the statistics of training runs, and reads and writes them as history files.
 package stats
 
 import (
-	"sync"
+	"maps"
 	"time"
 )
 
+// Training is the history of a training run, its epochs in order.
 type Training struct {
-	Start  time.Time
-	End    time.Time
-	Epochs sync.Map
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Epochs []*Epoch  `json:"epochs"`
+}
+
+// Add appends the statistics of the next epoch.
+func (t *Training) Add(e *Epoch) {
+	t.Epochs = append(t.Epochs, e)
+}
+
+// Epoch returns the statistics of the epoch with the given ID.
+func (t *Training) Epoch(id int) (*Epoch, bool) {
+	if id >= 0 && id < len(t.Epochs) && t.Epochs[id].ID == id {
+		return t.Epochs[id], true
+	}
+	for _, e := range t.Epochs {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// All calls yield with the statistics of every epoch in order, until it returns false.
+func (t *Training) All(yield func(Epoch) bool) {
+	for _, e := range t.Epochs {
+		if !yield(*e) {
+			return
+		}
+	}
+}
+
+// Clone returns a deep copy of the history.
+func (t *Training) Clone() *Training {
+	c := &Training{Start: t.Start, End: t.End, Epochs: make([]*Epoch, len(t.Epochs))}
+	for i, e := range t.Epochs {
+		ce := *e
+		ce.Metrics = maps.Clone(e.Metrics)
+		ce.ValidationMetrics = maps.Clone(e.ValidationMetrics)
+		c.Epochs[i] = &ce
+	}
+	return c
 }
 
 type Epoch struct {
-	ID     int
-	Start  time.Time
-	End    time.Time
-	Inputs int
+	ID     int       `json:"id"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Inputs int       `json:"inputs"`
+	// TrainLoss is the loss of the training inputs, averaged over the mini-batches of the epoch
+	// as they were trained on, so the network improves along the way.
+	TrainLoss float64 `json:"trainLoss"`
+	// ValidationLoss is the loss of the ValidationInputs at the end of the epoch, if any.
+	ValidationLoss   float64 `json:"validationLoss"`
+	ValidationInputs int     `json:"validationInputs"`
+	// Metrics of the training and validation inputs, by name.
+	Metrics           map[string]float64 `json:"metrics,omitempty"`
+	ValidationMetrics map[string]float64 `json:"validationMetrics,omitempty"`
+	LearningRate      float64            `json:"learningRate"`
+	// GradNorm is the L2 norm of the gradient, averaged over the mini-batches of the epoch.
+	GradNorm float64 `json:"gradNorm"`
+	// WeightNorm is the L2 norm of all weights and biases at the end of the epoch.
+	WeightNorm float64 `json:"weightNorm"`
 }
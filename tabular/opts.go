@@ -0,0 +1,173 @@
+package tabular
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Opt func(*tableOpts)
+
+type tableOpts struct {
+	comma       rune
+	comment     rune
+	header      bool
+	inputs      []string
+	targets     []string
+	trailing    int
+	ignore      []string
+	categorical map[string]Encoding
+	categories  map[string][]string
+	strategy    Strategy
+	missing     []string
+}
+
+var defaultTableOpts = tableOpts{
+	comma:   ',',
+	missing: []string{"", "NA", "N/A", "NaN", "nan", "null", "?"},
+}
+
+func (s *tableOpts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Comma sets the field delimiter, e.g. '\t' for TSV. Defaults to ','.
+func Comma(v rune) Opt {
+	return func(s *tableOpts) {
+		s.comma = v
+	}
+}
+
+// Comment sets the character starting comment lines, none by default.
+func Comment(v rune) Opt {
+	return func(s *tableOpts) {
+		s.comment = v
+	}
+}
+
+// Header tells whether the first row names the columns.
+func Header(v bool) Opt {
+	return func(s *tableOpts) {
+		s.header = v
+	}
+}
+
+// Inputs sets the input columns, in order. Defaults to all columns but targets and ignored ones.
+func Inputs(v ...string) Opt {
+	return func(s *tableOpts) {
+		s.inputs = v
+	}
+}
+
+// Targets sets the target columns, in order.
+func Targets(v ...string) Opt {
+	return func(s *tableOpts) {
+		s.targets = v
+	}
+}
+
+// TrailingTargets makes the last n columns targets.
+func TrailingTargets(n int) Opt {
+	return func(s *tableOpts) {
+		s.trailing = n
+	}
+}
+
+// Ignore excludes columns from the default inputs.
+func Ignore(v ...string) Opt {
+	return func(s *tableOpts) {
+		s.ignore = append(s.ignore, v...)
+	}
+}
+
+// Categorical encodes the values of the given columns as categories.
+func Categorical(e Encoding, columns ...string) Opt {
+	return func(s *tableOpts) {
+		if s.categorical == nil {
+			s.categorical = map[string]Encoding{}
+		}
+		for _, c := range columns {
+			s.categorical[c] = e
+		}
+	}
+}
+
+// Categories fixes the categories of categorical columns and their encoding order,
+// e.g. to the Categories of the training table so that validation and test files are encoded the same way.
+// Values of other categories are errors, categories of columns not read are ignored.
+func Categories(v map[string][]string) Opt {
+	return func(s *tableOpts) {
+		s.categories = v
+	}
+}
+
+// Missing sets what is done with missing values. Defaults to Fail.
+// Missing categorical values are a category of their own unless failing or dropped.
+func Missing(v Strategy) Opt {
+	return func(s *tableOpts) {
+		s.strategy = v
+	}
+}
+
+// MissingValues sets the values taken for missing, by default "", "NA", "N/A", "NaN", "nan", "null" and "?".
+func MissingValues(v ...string) Opt {
+	return func(s *tableOpts) {
+		s.missing = v
+	}
+}
+
+// columns resolves the columns used, inputs first, given the names of all columns.
+func (s *tableOpts) columns(names []string) ([]*column, error) {
+	find := func(name string) (int, error) {
+		i := slices.Index(names, name)
+		if i < 0 {
+			return 0, fmt.Errorf("no column %q in %v", name, names)
+		}
+		return i, nil
+	}
+
+	targets := slices.Clone(s.targets)
+	if s.trailing > 0 {
+		if s.trailing >= len(names) {
+			return nil, fmt.Errorf("%d target columns of %d", s.trailing, len(names))
+		}
+		targets = append(targets, names[len(names)-s.trailing:]...)
+	}
+	inputs := s.inputs
+	if inputs == nil {
+		for _, name := range names {
+			if !slices.Contains(targets, name) && !slices.Contains(s.ignore, name) {
+				inputs = append(inputs, name)
+			}
+		}
+	}
+
+	var columns []*column
+	for _, group := range []struct {
+		names  []string
+		target bool
+	}{{inputs, false}, {targets, true}} {
+		for _, name := range group.names {
+			i, err := find(name)
+			if err != nil {
+				return nil, err
+			}
+			c := &column{name: name, index: i, target: group.target}
+			c.encoding, c.categorical = s.categorical[name]
+			if categories, ok := s.categories[name]; ok && c.categorical {
+				c.categories, c.fixed = slices.Clone(categories), true
+			}
+			columns = append(columns, c)
+		}
+	}
+	for name := range s.categorical {
+		if _, err := find(name); err != nil {
+			return nil, err
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns used of %v", names)
+	}
+	return columns, nil
+}
@@ -0,0 +1,278 @@
+// Package tabular loads CSV and TSV files into inputs and targets ready for training.
+//
+// Columns are referred to by their header names, or by their 1-based positions ("1", "2", ...)
+// in files without a header. Unless told otherwise, target columns are excluded from the inputs
+// and every other column is an input, parsed as a number. Categorical columns are encoded
+// one-hot or as the index of their category, in order of first appearance unless the categories are set.
+package tabular
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/lnashier/gonet/dataset"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Encoding is how the values of a categorical column are turned into numbers.
+type Encoding int
+
+const (
+	// OneHot encodes a value as as many features as the column has categories, all zero but one.
+	OneHot Encoding = iota
+	// Index encodes a value as the index of its category.
+	Index
+)
+
+// Strategy is what is done with missing values.
+type Strategy int
+
+const (
+	// Fail reports missing values as errors.
+	Fail Strategy = iota
+	// Zero replaces missing numbers with zero.
+	Zero
+	// Mean replaces missing numbers with the mean of their column.
+	Mean
+	// Drop skips the rows with missing values.
+	Drop
+)
+
+// ParseError reports a value that couldn't be used, with its position in the file.
+type ParseError struct {
+	Row    int // 1-based line of the file
+	Column int // 1-based
+	Name   string
+	Value  string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("row %d, column %d (%s): %q: %v", e.Row, e.Column, e.Name, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrMissing is the error of missing values under the Fail strategy.
+var ErrMissing = errors.New("missing value")
+
+// ErrUnknownCategory is the error of values outside the categories set with Categories.
+var ErrUnknownCategory = errors.New("unknown category")
+
+// Table is a loaded file.
+type Table struct {
+	Inputs  [][]float64
+	Targets [][]float64
+	// InputNames and TargetNames name the features, "column=category" for one-hot encoded ones.
+	InputNames  []string
+	TargetNames []string
+	// Categories lists the categories of every categorical column, in encoding order.
+	Categories map[string][]string
+}
+
+// Dataset returns the rows of the table as a dataset.
+func (t *Table) Dataset() *dataset.Slices {
+	return dataset.FromSlices(t.Inputs, t.Targets)
+}
+
+// Load reads the named file, tab-separated if its name ends with .tsv unless set otherwise.
+func Load(name string, opt ...Opt) (*Table, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if filepath.Ext(name) == ".tsv" {
+		opt = append([]Opt{Comma('\t')}, opt...)
+	}
+	t, err := Read(f, opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// column is the role of a column of the file.
+type column struct {
+	name        string
+	index       int
+	target      bool
+	categorical bool
+	encoding    Encoding
+	categories  []string
+	fixed       bool // categories set, not collected
+	mean        float64
+}
+
+// Read reads CSV data.
+func Read(r io.Reader, opt ...Opt) (*Table, error) {
+	opts := defaultTableOpts
+	opts.apply(opt)
+
+	cr := csv.NewReader(r)
+	cr.Comma = opts.comma
+	cr.Comment = opts.comment
+	cr.TrimLeadingSpace = true
+	var records [][]string
+	var rows []int // lines of the records
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				return nil, &ParseError{Row: pe.Line, Column: pe.Column, Err: pe.Err}
+			}
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		records = append(records, record)
+		rows = append(rows, line)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no rows")
+	}
+
+	var names []string
+	if opts.header {
+		names = records[0]
+		records, rows = records[1:], rows[1:]
+	} else {
+		for i := range records[0] {
+			names = append(names, strconv.Itoa(i+1))
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no rows")
+	}
+
+	columns, err := opts.columns(names)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := func(v string) bool {
+		return slices.Contains(opts.missing, strings.TrimSpace(v))
+	}
+
+	// drop incomplete rows first so that means and categories only see rows kept
+	kept, keptRows := records[:0], rows[:0]
+	for i, record := range records {
+		drop := false
+		for _, c := range columns {
+			if missing(record[c.index]) {
+				switch opts.strategy {
+				case Fail:
+					return nil, &ParseError{Row: rows[i], Column: c.index + 1, Name: c.name, Value: record[c.index], Err: ErrMissing}
+				case Drop:
+					drop = true
+				}
+			}
+		}
+		if !drop {
+			kept = append(kept, record)
+			keptRows = append(keptRows, rows[i])
+		}
+	}
+	records, rows = kept, keptRows
+	if len(records) == 0 {
+		return nil, errors.New("no rows left without missing values")
+	}
+
+	// categories and means
+	values := make([][]float64, len(records))
+	for i := range values {
+		values[i] = make([]float64, len(names))
+	}
+	for _, c := range columns {
+		if c.categorical {
+			for i, record := range records {
+				v := strings.TrimSpace(record[c.index])
+				if slices.Contains(c.categories, v) {
+					continue
+				}
+				if c.fixed {
+					return nil, &ParseError{Row: rows[i], Column: c.index + 1, Name: c.name, Value: v, Err: ErrUnknownCategory}
+				}
+				c.categories = append(c.categories, v)
+			}
+			continue
+		}
+		sum, n := 0.0, 0
+		for i, record := range records {
+			v := strings.TrimSpace(record[c.index])
+			if missing(v) {
+				continue
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, &ParseError{Row: rows[i], Column: c.index + 1, Name: c.name, Value: v, Err: err}
+			}
+			values[i][c.index] = f
+			sum += f
+			n++
+		}
+		if n > 0 {
+			c.mean = sum / float64(n)
+		}
+	}
+
+	t := &Table{
+		Inputs:     make([][]float64, len(records)),
+		Targets:    make([][]float64, len(records)),
+		Categories: map[string][]string{},
+	}
+	for _, c := range columns {
+		names := []string{c.name}
+		if c.categorical {
+			t.Categories[c.name] = c.categories
+			if c.encoding == OneHot {
+				names = names[:0]
+				for _, category := range c.categories {
+					names = append(names, c.name+"="+category)
+				}
+			}
+		}
+		if c.target {
+			t.TargetNames = append(t.TargetNames, names...)
+		} else {
+			t.InputNames = append(t.InputNames, names...)
+		}
+	}
+	for i, record := range records {
+		inputs := make([]float64, 0, len(t.InputNames))
+		targets := make([]float64, 0, len(t.TargetNames))
+		for _, c := range columns {
+			var features []float64
+			v := strings.TrimSpace(record[c.index])
+			switch {
+			case c.categorical && c.encoding == OneHot:
+				features = make([]float64, len(c.categories))
+				features[slices.Index(c.categories, v)] = 1
+			case c.categorical:
+				features = []float64{float64(slices.Index(c.categories, v))}
+			case missing(v) && opts.strategy == Mean:
+				features = []float64{c.mean}
+			default:
+				features = []float64{values[i][c.index]}
+			}
+			if c.target {
+				targets = append(targets, features...)
+			} else {
+				inputs = append(inputs, features...)
+			}
+		}
+		t.Inputs[i], t.Targets[i] = inputs, targets
+	}
+	return t, nil
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"github.com/lnashier/gonet"
+	"github.com/lnashier/gonet/metrics"
+	"math"
+	"slices"
+)
+
+// Metric scores predictions against targets, e.g. one of package metrics or a loss function of package fns.
+type Metric = metrics.Metric
+
+// Result summarizes a metric over the folds of a cross-validation.
+type Result struct {
+	Metric string
+	Folds  []float64
+	Mean   float64
+	Std    float64
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s: %.6f ± %.6f", r.Metric, r.Mean, r.Std)
+}
+
+// CrossValidate trains a new network from factory on the training examples of every fold
+// and scores its predictions of the test examples with every metric of scores. Results are ordered by metric name.
+// It returns the error of ctx once cancelled, even while the last fold trains.
+func CrossValidate(ctx context.Context, folds []Fold, inputs, targets [][]float64,
+	factory func(fold int) gonet.Network, scores map[string]Metric, opt ...Opt) ([]Result, error) {
+	opts := defaultCrossValidationOpts
+	opts.apply(opt)
+
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	results := make([]Result, len(names))
+	for i, name := range names {
+		results[i] = Result{Metric: name, Folds: make([]float64, len(folds))}
+	}
+
+	for f, fold := range folds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		nn := factory(f)
+		opts.train(ctx, nn, Select(inputs, fold.Train), Select(targets, fold.Train))
+		// training stops early once cancelled, its network isn't scored
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		testTargets := Select(targets, fold.Test)
+		predictions := make([][]float64, len(fold.Test))
+		for i, e := range fold.Test {
+			predictions[i] = nn.Predict(inputs[e])
+		}
+		for i, name := range names {
+			results[i].Folds[f] = scores[name](predictions, testTargets)
+		}
+	}
+
+	for i := range results {
+		results[i].Mean, results[i].Std = meanStd(results[i].Folds)
+	}
+	return results, nil
+}
+
+// meanStd returns the mean and the sample standard deviation of values.
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v / float64(len(values))
+	}
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)-1))
+}
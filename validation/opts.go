@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"context"
+	"github.com/lnashier/gonet"
+)
+
+type Opt func(*crossValidationOpts)
+
+type crossValidationOpts struct {
+	train func(ctx context.Context, nn gonet.Network, inputs, targets [][]float64)
+}
+
+var defaultCrossValidationOpts = crossValidationOpts{
+	train: epochs(10),
+}
+
+func (s *crossValidationOpts) apply(opts []Opt) {
+	for _, o := range opts {
+		o(s)
+	}
+}
+
+// Epochs sets the number of epochs every network is trained for. Defaults to 10.
+func Epochs(v int) Opt {
+	return func(s *crossValidationOpts) {
+		s.train = epochs(v)
+	}
+}
+
+// Train sets how every network is trained, e.g. with help.Train.
+func Train(v func(ctx context.Context, nn gonet.Network, inputs, targets [][]float64)) Opt {
+	return func(s *crossValidationOpts) {
+		s.train = v
+	}
+}
+
+func epochs(n int) func(ctx context.Context, nn gonet.Network, inputs, targets [][]float64) {
+	return func(ctx context.Context, nn gonet.Network, inputs, targets [][]float64) {
+		nn.Train(n, inputs, targets, func(int) bool {
+			return ctx.Err() == nil
+		})
+	}
+}
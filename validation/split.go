@@ -0,0 +1,170 @@
+// Package validation splits data into training, validation and test sets
+// and cross-validates networks over folds.
+//
+// Splits are expressed as indices of examples, see Select and dataset.Subset.
+// They only depend on their seed.
+package validation
+
+import (
+	"fmt"
+	"github.com/lnashier/gonet/fns"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// Split shuffles n examples and splits them in parts of the given fractions, e.g. 0.8, 0.1, 0.1.
+// Fractions summing to less than 1 leave examples out, rounding goes to the last part.
+func Split(n int, seed int64, fractions ...float64) ([][]int, error) {
+	if err := checkFractions(fractions); err != nil {
+		return nil, err
+	}
+	return cut(rand.New(rand.NewSource(seed)).Perm(n), fractions), nil
+}
+
+// StratifiedSplit splits like Split, preserving the proportions of the classes of labels in every part.
+func StratifiedSplit(labels []int, seed int64, fractions ...float64) ([][]int, error) {
+	if err := checkFractions(fractions); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(seed))
+	parts := make([][]int, len(fractions))
+	for _, class := range classes(labels) {
+		r.Shuffle(len(class), func(i, j int) {
+			class[i], class[j] = class[j], class[i]
+		})
+		for i, part := range cut(class, fractions) {
+			parts[i] = append(parts[i], part...)
+		}
+	}
+	for _, part := range parts {
+		r.Shuffle(len(part), func(i, j int) {
+			part[i], part[j] = part[j], part[i]
+		})
+	}
+	return parts, nil
+}
+
+// Fold is one round of cross-validation, testing on examples trained on in the other rounds.
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// KFold shuffles n examples into k folds of nearly equal test sets.
+func KFold(n, k int, seed int64) ([]Fold, error) {
+	if k < 2 || k > n {
+		return nil, fmt.Errorf("validation: %d folds of %d examples", k, n)
+	}
+	perm := rand.New(rand.NewSource(seed)).Perm(n)
+	tests := make([][]int, k)
+	for i, e := range perm {
+		tests[i%k] = append(tests[i%k], e)
+	}
+	return folds(tests), nil
+}
+
+// StratifiedKFold splits like KFold, preserving the proportions of the classes of labels in every fold.
+func StratifiedKFold(labels []int, k int, seed int64) ([]Fold, error) {
+	if k < 2 || k > len(labels) {
+		return nil, fmt.Errorf("validation: %d folds of %d examples", k, len(labels))
+	}
+	r := rand.New(rand.NewSource(seed))
+	tests := make([][]int, k)
+	next := 0 // deals classes on from the fold the previous one ended at, balancing fold sizes
+	for _, class := range classes(labels) {
+		r.Shuffle(len(class), func(i, j int) {
+			class[i], class[j] = class[j], class[i]
+		})
+		for _, e := range class {
+			tests[next%k] = append(tests[next%k], e)
+			next++
+		}
+	}
+	return folds(tests), nil
+}
+
+// Labels returns the class of every target, the index of its largest value or,
+// for single values, the value rounded.
+func Labels(targets [][]float64) []int {
+	labels := make([]int, len(targets))
+	for i, t := range targets {
+		if len(t) == 1 {
+			labels[i] = int(math.Round(t[0]))
+		} else {
+			labels[i] = fns.Argmax(t)
+		}
+	}
+	return labels
+}
+
+// Select returns the rows of data at the given indices.
+func Select[T any](data []T, indices []int) []T {
+	result := make([]T, len(indices))
+	for i, j := range indices {
+		result[i] = data[j]
+	}
+	return result
+}
+
+func folds(tests [][]int) []Fold {
+	result := make([]Fold, len(tests))
+	for i, test := range tests {
+		result[i].Test = test
+		for j, other := range tests {
+			if j != i {
+				result[i].Train = append(result[i].Train, other...)
+			}
+		}
+		slices.Sort(result[i].Train)
+	}
+	return result
+}
+
+// classes groups the indices of labels by class, classes in increasing order.
+func classes(labels []int) [][]int {
+	byClass := map[int][]int{}
+	var keys []int
+	for i, l := range labels {
+		if _, ok := byClass[l]; !ok {
+			keys = append(keys, l)
+		}
+		byClass[l] = append(byClass[l], i)
+	}
+	slices.Sort(keys)
+	result := make([][]int, len(keys))
+	for i, k := range keys {
+		result[i] = byClass[k]
+	}
+	return result
+}
+
+// cut splits indices in consecutive parts of the given fractions.
+func cut(indices []int, fractions []float64) [][]int {
+	parts := make([][]int, len(fractions))
+	start, total := 0, 0.0
+	for i, f := range fractions {
+		total += f
+		end := int(math.Round(total * float64(len(indices))))
+		if i == len(fractions)-1 && total > 1-1e-9 {
+			end = len(indices)
+		}
+		parts[i] = indices[start:end:end]
+		start = end
+	}
+	return parts
+}
+
+func checkFractions(fractions []float64) error {
+	total := 0.0
+	for _, f := range fractions {
+		if f < 0 {
+			return fmt.Errorf("validation: negative fraction %v", f)
+		}
+		total += f
+	}
+	if len(fractions) == 0 || total > 1+1e-9 {
+		return fmt.Errorf("validation: fractions %v", fractions)
+	}
+	return nil
+}